@@ -2,22 +2,168 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 
 	"github.com/mo-work/go-technical-test-for-claudia/editor"
+	"github.com/mo-work/go-technical-test-for-claudia/repl"
 	"github.com/mo-work/go-technical-test-for-claudia/runner"
+	"github.com/mo-work/go-technical-test-for-claudia/server"
+)
+
+// Exit statuses, so that scripted callers can tell what went wrong.
+const (
+	exitFailure = 1
+	exitParse   = 2
+	exitRange   = 3
+	exitIO      = 4
 )
 
 func main() {
-	editor := editor.Editor{}
-	r := runner.New(bufio.NewScanner(os.Stdin), os.Stdout, &editor)
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+	}
+
+	pngPath := flag.String("png", "", "export the final image to this PNG file")
+	pngScale := flag.Int("scale", 1, "width and height of each image pixel in the PNG")
+	strict := flag.Bool("strict", false, "stop at the first failed command and exit with a non-zero status")
+	colour := flag.Bool("color", false, "show images as coloured blocks when writing to a terminal")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [script ...]\n       %s serve [-addr address]\n\nRuns each script in order, or standard input if none are given ('-' also reads standard input).\n\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	r := runner.New(nil, os.Stdout, &editor.Editor{})
+	r.SetEditorFactory(func() runner.ImageEditor { return &editor.Editor{} })
+	r.SetStrict(*strict)
+	r.SetColour(*colour && repl.IsTerminal(os.Stdout))
+	r.SetColours(colours())
 
-	if err := r.ProcessImageSize(); err != nil {
-		fmt.Printf("invalid image value: %s\n", err)
+	scripts := flag.Args()
+	if len(scripts) == 0 && repl.IsTerminal(os.Stdin) {
+		if err := interact(r); err != nil {
+			fmt.Println(err)
+			os.Exit(exitIO)
+		}
+	} else if len(scripts) == 0 {
+		r.SetInput(bufio.NewScanner(os.Stdin))
+		run(r, true)
 	}
 
-	r.ProcessEditActions()
+	for i, script := range scripts {
+		in, err := open(script)
+		if err != nil {
+			fmt.Printf("could not open script: %s\n", err)
+			os.Exit(exitIO)
+		}
+
+		r.SetInput(bufio.NewScanner(in))
+		r.SetSource(name(script))
+		run(r, i == 0)
+		in.Close()
+	}
+
+	if *pngPath != "" {
+		if err := r.Export(*pngPath, *pngScale); err != nil {
+			fmt.Printf("could not export png: %s\n", err)
+			os.Exit(exitIO)
+		}
+	}
 
 	os.Exit(0)
 }
+
+// run processes one input, exiting if it fails. Only the first input has to
+// start by creating the image; later ones carry on editing it.
+func run(r *runner.Runner, first bool) {
+	if first {
+		if err := r.ProcessImageSize(); err != nil {
+			fmt.Printf("invalid image value: %s\n", err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	if err := r.ProcessEditActions(); err != nil {
+		fmt.Println(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// serve runs the HTTP API until the server fails.
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	flags.Parse(args)
+
+	s := server.New(func() runner.ImageEditor { return &editor.Editor{} })
+
+	fmt.Printf("listening on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, s); err != nil {
+		fmt.Println(err)
+		os.Exit(exitIO)
+	}
+}
+
+// interact runs an interactive shell on the terminal, editing lines itself
+// when the terminal can be put into raw mode.
+func interact(r *runner.Runner) error {
+	var lines repl.LineReader = repl.NewPlain(os.Stdin, os.Stdout)
+
+	restore, err := repl.MakeRaw(os.Stdin.Fd())
+	if err == nil {
+		defer restore()
+		lines = repl.NewEditor(os.Stdin, os.Stdout, repl.Complete)
+	}
+
+	return repl.New(r, lines, os.Stdout).Run()
+}
+
+// colours is how many colours the terminal can show, going by COLORTERM,
+// which terminals with truecolor set.
+func colours() int {
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return editor.TrueColour
+	}
+
+	return editor.ANSI256
+}
+
+func exitCode(err error) int {
+	var (
+		parseErr *runner.ParseError
+		fileErr  *runner.FileError
+	)
+
+	switch {
+	case errors.As(err, &parseErr), errors.Is(err, editor.ErrUnknownColour):
+		return exitParse
+	case errors.Is(err, editor.ErrOutOfBounds), errors.Is(err, runner.ErrImageSize), errors.Is(err, runner.ErrArgumentRange):
+		return exitRange
+	case errors.As(err, &fileErr):
+		return exitIO
+	}
+
+	return exitFailure
+}
+
+func open(script string) (io.ReadCloser, error) {
+	if script == "-" {
+		return os.Stdin, nil
+	}
+
+	return os.Open(script)
+}
+
+func name(script string) string {
+	if script == "-" {
+		return "stdin"
+	}
+
+	return script
+}
@@ -0,0 +1,60 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+)
+
+// Colour depths for RenderRegion: the number of colours a terminal can show.
+const (
+	ANSI256    = 256
+	TrueColour = 1 << 24
+)
+
+// blockWidth is how many characters wide a pixel is drawn as a coloured
+// block, so that it comes out roughly square.
+const blockWidth = 2
+
+const ansiReset = "\x1b[0m"
+
+// blocks writes the pixels of row y from x1 to x2 as blocks of background
+// colour, taken from the same palette as PNG. The colour is only set where it
+// changes, and reset at the end of the row.
+func (e Editor) blocks(buf *bytes.Buffer, x1, x2, y, colours int) {
+	var last byte
+	for x := x1; x <= x2; x++ {
+		char := e.at(x, y)
+		if x == x1 || char != last {
+			c := e.rgb(string(char))
+			if colours >= TrueColour {
+				fmt.Fprintf(buf, "\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+			} else {
+				fmt.Fprintf(buf, "\x1b[48;5;%dm", xterm256(c))
+			}
+		}
+		last = char
+
+		for i := 0; i < blockWidth; i++ {
+			buf.WriteByte(' ')
+		}
+	}
+
+	buf.WriteString(ansiReset)
+}
+
+// xterm256 is the nearest colour to c in the 6x6x6 cube of the 256-colour
+// palette.
+func xterm256(c color.RGBA) int {
+	level := func(v uint8) int {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
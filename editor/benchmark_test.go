@@ -0,0 +1,56 @@
+package editor_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mo-work/go-technical-test-for-claudia/editor"
+)
+
+// benchmarkImage is a 1024x1024 image with something drawn on every tile.
+func benchmarkImage() *editor.Editor {
+	e := &editor.Editor{}
+	e.CreateImage(1024, 1024)
+	e.FilledCircle(512, 512, 400, "R")
+	e.Line(1, 1, 1024, 1024, "B")
+
+	return e
+}
+
+func BenchmarkRender(b *testing.B) {
+	e := benchmarkImage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := e.Render(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPretty(b *testing.B) {
+	e := benchmarkImage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = e.Pretty()
+	}
+}
+
+// BenchmarkConcatenate shows the cost of building the text a row at a time
+// with +=, as Pretty once did, for comparison.
+func BenchmarkConcatenate(b *testing.B) {
+	rows := strings.SplitAfter(benchmarkImage().Pretty(), "\n")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out := ""
+		for _, row := range rows {
+			out += row
+		}
+	}
+}
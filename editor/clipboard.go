@@ -0,0 +1,138 @@
+package editor
+
+import "errors"
+
+// picture is an image's visible layers combined into one sheet of pixels,
+// for pasting into another image. Like a layer it is kept in tiles, so a
+// large picture with little drawn on it costs little.
+type picture struct {
+	pixels *canvas
+}
+
+// Copy keeps the pixels of the rectangle with opposite corners (x1,y1) and
+// (x2,y2) for Paste. The whole rectangle must lie on the grid.
+func (e *Editor) Copy(x1, y1, x2, y2 int) error {
+	region, err := e.region(x1, y1, x2, y2)
+	if err != nil {
+		return err
+	}
+
+	e.clipboard = region
+
+	return nil
+}
+
+// Paste draws the copied pixels with their top left corner at (x,y). Any
+// part that falls off the grid is left out.
+func (e *Editor) Paste(x, y int) error {
+	if e.clipboard == nil {
+		return errors.New("nothing to paste")
+	}
+
+	defer e.history.commit()
+
+	e.stamp(e.clipboard, x, y)
+
+	return nil
+}
+
+// Picture combines the visible layers for PastePicture, which may be another
+// editor's. The result means nothing to anyone else. Only the tiles drawn on
+// are visited.
+func (e Editor) Picture() interface{} {
+	out := newCanvas(e.cols, e.rows, e.background())
+
+	for _, l := range e.layers {
+		if l.hidden {
+			continue
+		}
+
+		l.pixels.each(func(x, y int, _ byte) {
+			out.set(x, y, e.at(x, y))
+		})
+	}
+
+	return &picture{pixels: out}
+}
+
+// PastePicture draws a picture from Picture with its top left corner at
+// (x,y). Any part that falls off the grid is left out.
+func (e *Editor) PastePicture(p interface{}, x, y int) error {
+	pic, ok := p.(*picture)
+	if !ok {
+		return errors.New("not a picture from an editor")
+	}
+
+	defer e.history.commit()
+
+	e.stamp(pic.pixels, x, y)
+
+	return nil
+}
+
+// Move shifts the rectangle with opposite corners (x1,y1) and (x2,y2) by dx
+// columns and dy rows, leaving the layer's blank colour where it was: the
+// background colour on the base layer, transparent on the rest. Any part
+// moved off the grid is lost.
+func (e *Editor) Move(x1, y1, x2, y2, dx, dy int) error {
+	region, err := e.region(x1, y1, x2, y2)
+	if err != nil {
+		return err
+	}
+
+	x1, y1 = min(x1, x2), min(y1, y2)
+
+	defer e.history.commit()
+
+	e.stamp(newCanvas(region.cols, region.rows, e.layer().pixels.blank), x1, y1)
+	e.stamp(region, x1+dx, y1+dy)
+
+	return nil
+}
+
+// region copies out the pixels of a rectangle on the grid. Working from a
+// copy lets a region be drawn over the place it came from.
+func (e *Editor) region(x1, y1, x2, y2 int) (*canvas, error) {
+	if !e.contains(x1, y1) || !e.contains(x2, y2) {
+		return nil, ErrOutOfBounds
+	}
+
+	x1, x2 = ordered(x1, x2)
+	y1, y2 = ordered(y1, y2)
+
+	return e.layer().pixels.transform(x2-x1+1, y2-y1+1, func(x, y int) (int, int) {
+		return x + x1 - 1, y + y1 - 1
+	}), nil
+}
+
+// stamp draws src on the layer with its top left corner at (x,y), leaving out
+// any part off the grid. Where neither the layer nor src has anything drawn,
+// and they share a blank colour, a whole tile is skipped, so stamping a large
+// and mostly blank canvas costs little.
+func (e *Editor) stamp(src *canvas, x, y int) {
+	dst := e.layer().pixels
+
+	x1, y1 := max(x, 1), max(y, 1)
+	x2, y2 := min(x+src.cols-1, e.cols), min(y+src.rows-1, e.rows)
+	if x1 > x2 || y1 > y2 {
+		return
+	}
+
+	for ty := (y1 - 1) / tileSize; ty <= (y2-1)/tileSize; ty++ {
+		for tx := (x1 - 1) / tileSize; tx <= (x2-1)/tileSize; tx++ {
+			key := point{tx, ty}
+			bx1, by1, bx2, by2 := dst.bounds(key)
+			bx1, by1, bx2, by2 = max(bx1, x1), max(by1, y1), min(bx2, x2), min(by2, y2)
+
+			if dst.tiles[key] == nil && dst.blank == src.blank && !src.drawnWithin(bx1-x+1, by1-y+1, bx2-x+1, by2-y+1) {
+				continue
+			}
+
+			for py := by1; py <= by2; py++ {
+				for px := bx1; px <= bx2; px++ {
+					e.paint(px, py, src.get(px-x+1, py-y+1))
+				}
+			}
+		}
+	}
+}
@@ -1,79 +1,474 @@
 package editor
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
 	"strings"
 )
 
+// ErrOutOfBounds is returned when an operation reaches past the image grid.
+var ErrOutOfBounds = errors.New("given coordinate is beyond image grid")
+
 type Editor struct {
-	Image [][]string
-	rows  int
-	cols  int
+	// RGB sets the colours used by PNG, overriding DefaultRGBPalette.
+	RGB RGBPalette
+	// Palette sets the colours that may be drawn with, in place of
+	// DefaultPalette.
+	Palette   *Palette
+	rows      int
+	cols      int
+	history   history
+	clipboard *canvas
+	layers    []*layer
+	active    int
+}
+
+type point struct {
+	x, y int
 }
 
+// CreateImage replaces the image, and any layers, with a single layer of the
+// background colour.
 func (e *Editor) CreateImage(c, r int) {
 	e.rows, e.cols = r, c
-	e.clear()
+	e.reset(newCanvas(c, r, e.background()))
+}
+
+func (e *Editor) Set(x, y int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	return e.set(x, y, char)
+}
+
+func (e *Editor) SetMultiY(x, y1, y2 int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	return e.setMultiY(x, y1, y2, char)
+}
+
+func (e *Editor) SetMultiX(x1, x2, y int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	return e.setMultiX(x1, x2, y, char)
+}
+
+func (e *Editor) Rect(x1, y1, x2, y2 int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	x1, x2 = ordered(x1, x2)
+	y1, y2 = ordered(y1, y2)
+
+	defer e.history.commit()
+
+	for _, lineErr := range []error{
+		e.setMultiX(x1, x2, y1, char),
+		e.setMultiX(x1, x2, y2, char),
+		e.setMultiY(x1, y1, y2, char),
+		e.setMultiY(x2, y1, y2, char),
+	} {
+		if lineErr != nil {
+			err = lineErr
+		}
+	}
+
+	return err
+}
+
+func (e *Editor) Box(x1, y1, x2, y2 int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	y1, y2 = ordered(y1, y2)
+
+	defer e.history.commit()
+
+	// Rows off the grid are skipped, however many there are.
+	if y1 < 1 || y2 > e.rows {
+		err = ErrOutOfBounds
+	}
+	if y1 < 1 {
+		y1 = 1
+	}
+	if y2 > e.rows {
+		y2 = e.rows
+	}
+
+	for y := y1; y <= y2; y++ {
+		if lineErr := e.setMultiX(x1, x2, y, char); lineErr != nil {
+			err = lineErr
+		}
+	}
+
+	return err
+}
+
+// Line draws from (x1,y1) to (x2,y2) inclusive using Bresenham's algorithm.
+// Points that fall off the grid are skipped and reported as an error once the
+// rest of the line has been drawn.
+func (e *Editor) Line(x1, y1, x2, y2 int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	dx, dy := abs(x2-x1), -abs(y2-y1)
+	sx, sy := sign(x2-x1), sign(y2-y1)
+	diff := dx + dy
+
+	for x, y := x1, y1; ; {
+		if setErr := e.set(x, y, char); setErr != nil {
+			err = setErr
+		}
+
+		if x == x2 && y == y2 {
+			break
+		}
+
+		d := 2 * diff
+		if d >= dy {
+			diff += dy
+			x += sx
+		}
+		if d <= dx {
+			diff += dx
+			y += sy
+		}
+	}
+
+	return err
+}
+
+func (e *Editor) Fill(x, y int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+	if !e.contains(x, y) {
+		return ErrOutOfBounds
+	}
+
+	pixels := e.layer().pixels
+	target := pixels.get(x, y)
+	if target == char {
+		return nil
+	}
+
+	defer e.history.commit()
+
+	// A queue rather than recursion: a region can span the whole grid, which
+	// would be far too deep a call stack on a large image.
+	queue := []point{{x, y}}
+	e.paint(x, y, char)
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, n := range []point{{p.x - 1, p.y}, {p.x + 1, p.y}, {p.x, p.y - 1}, {p.x, p.y + 1}} {
+			if !e.contains(n.x, n.y) || pixels.get(n.x, n.y) != target {
+				continue
+			}
+			e.paint(n.x, n.y, char)
+			queue = append(queue, n)
+		}
+	}
+
+	return nil
+}
+
+func (e Editor) Size() (int, int) {
+	return e.cols, e.rows
+}
+
+// Pretty shows the visible layers combined into one image.
+func (e Editor) Pretty() string {
+	var out strings.Builder
+	out.Grow((e.cols + 1) * e.rows)
+	e.Render(&out)
+
+	return out.String()
+}
+
+// Render writes the visible layers combined into one image, a row at a time,
+// so that the whole image is never held in memory as text.
+func (e Editor) Render(w io.Writer) error {
+	return e.render(w, 1, 1, e.cols, e.rows, false, 0)
 }
 
-func (e *Editor) Set(x, y int, char string) error {
-	if x > e.cols || y > e.rows {
-		return errors.New("given coordinate is beyond image grid")
+// RenderRegion writes the rectangle with opposite corners (x1,y1) and (x2,y2)
+// as Render does. With rulers, the column numbers are written downwards above
+// it, one digit to a line, and each row starts with its number. colours is
+// how many colours the terminal can show: 0 to write letters, or ANSI256 or
+// TrueColour to draw each pixel as a coloured block.
+func (e Editor) RenderRegion(w io.Writer, x1, y1, x2, y2 int, rulers bool, colours int) error {
+	if !e.contains(x1, y1) || !e.contains(x2, y2) {
+		return ErrOutOfBounds
 	}
 
-	e.Image[y-1][x-1] = char
+	x1, x2 = ordered(x1, x2)
+	y1, y2 = ordered(y1, y2)
+
+	return e.render(w, x1, y1, x2, y2, rulers, colours)
+}
+
+func (e Editor) render(w io.Writer, x1, y1, x2, y2 int, rulers bool, colours int) error {
+	bw := bufio.NewWriter(w)
+
+	width := 1
+	if colours != 0 {
+		width = blockWidth
+	}
+
+	margin := 0
+	if rulers {
+		margin = len(strconv.Itoa(y2)) + 1
+		if err := columnRuler(bw, x1, x2, margin, width); err != nil {
+			return err
+		}
+	}
+
+	var row bytes.Buffer
+	for y := y1; y <= y2; y++ {
+		row.Reset()
+		if rulers {
+			fmt.Fprintf(&row, "%*d ", margin-1, y)
+		}
+		if colours != 0 {
+			e.blocks(&row, x1, x2, y, colours)
+		} else {
+			for x := x1; x <= x2; x++ {
+				row.WriteByte(e.at(x, y))
+			}
+		}
+		row.WriteByte('\n')
+
+		if _, err := bw.Write(row.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// columnRuler writes the numbers of columns x1 to x2 downwards, one digit to a
+// line and indented by margin, so that each lines up with its column.
+func columnRuler(w io.Writer, x1, x2, margin, width int) error {
+	digits := len(strconv.Itoa(x2))
+
+	line := make([]byte, margin+(x2-x1)*width+2)
+	for d := 0; d < digits; d++ {
+		for i := range line {
+			line[i] = ' '
+		}
+		line[len(line)-1] = '\n'
+
+		for x := x1; x <= x2; x++ {
+			label := strconv.Itoa(x)
+			if pad := digits - len(label); d >= pad {
+				line[margin+(x-x1)*width] = label[d-pad]
+			}
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear empties the layer being drawn on: the background colour on the base
+// layer, transparent on the rest. It takes time in proportion to the tiles
+// drawn on, not to the size of the image.
+func (e *Editor) Clear() {
+	if len(e.layers) == 0 || !e.layer().pixels.drawn() {
+		return
+	}
+
+	e.restructure(func() {
+		l := e.layer()
+		l.pixels = newCanvas(l.pixels.cols, l.pixels.rows, l.pixels.blank)
+	})
+}
+
+func (e *Editor) Undo() error {
+	ed, ok := e.history.undo()
+	if !ok {
+		return errors.New("nothing to undo")
+	}
+
+	ed.swap()
+	if ed.from != nil {
+		e.restore(*ed.from)
+	}
+
+	return nil
+}
+
+func (e *Editor) Redo() error {
+	ed, ok := e.history.redo()
+	if !ok {
+		return errors.New("nothing to redo")
+	}
+
+	ed.swap()
+	if ed.to != nil {
+		e.restore(*ed.to)
+	}
 
 	return nil
 }
 
-func (e *Editor) SetMultiY(x, y1, y2 int, char string) error {
-	if y1 > y2 {
-		y1, y2 = y2, y1
+// reset starts afresh with a single base layer holding the given pixels.
+func (e *Editor) reset(pixels *canvas) {
+	e.layers = []*layer{{name: baseLayer, pixels: pixels}}
+	e.active = 0
+	e.history = history{}
+}
+
+func (e *Editor) set(x, y int, char byte) error {
+	if !e.contains(x, y) {
+		return ErrOutOfBounds
 	}
 
+	e.paint(x, y, char)
+
+	return nil
+}
+
+// setMultiY colours the part of a vertical run on the grid, visiting only
+// that part however long the run is, and reports it if any fell off.
+func (e *Editor) setMultiY(x, y1, y2 int, char byte) error {
+	y1, y2 = ordered(y1, y2)
+
 	var err error
+	if !e.contains(x, y1) || !e.contains(x, y2) {
+		err = ErrOutOfBounds
+	}
+	if x < 1 || x > e.cols {
+		return err
+	}
+
+	if y1 < 1 {
+		y1 = 1
+	}
+	if y2 > e.rows {
+		y2 = e.rows
+	}
 	for y := y1; y <= y2; y++ {
-		err = e.Set(x, y, char)
+		e.paint(x, y, char)
 	}
 
 	return err
 }
 
-func (e *Editor) SetMultiX(x1, x2, y int, char string) error {
-	if x1 > x2 {
-		x1, x2 = x2, x1
-	}
+// setMultiX is setMultiY for a horizontal run.
+func (e *Editor) setMultiX(x1, x2, y int, char byte) error {
+	x1, x2 = ordered(x1, x2)
 
 	var err error
+	if !e.contains(x1, y) || !e.contains(x2, y) {
+		err = ErrOutOfBounds
+	}
+	if y < 1 || y > e.rows {
+		return err
+	}
+
+	if x1 < 1 {
+		x1 = 1
+	}
+	if x2 > e.cols {
+		x2 = e.cols
+	}
 	for x := x1; x <= x2; x++ {
-		err = e.Set(x, y, char)
+		e.paint(x, y, char)
 	}
 
 	return err
 }
 
-func (e Editor) Pretty() string {
-	out := ""
-	for x := range e.Image {
-		out += strings.Join(e.Image[x], "") + "\n"
+// paint colours a pixel known to be on the grid, noting the change so that it
+// can be undone.
+func (e *Editor) paint(x, y int, char byte) {
+	l := e.layer()
+
+	from := l.pixels.get(x, y)
+	if from == char {
+		return
 	}
 
-	return out
+	e.history.touch(l.pixels, x, y)
+	l.pixels.set(x, y, char)
 }
 
-func (e *Editor) Clear() {
-	e.clear()
+func (e *Editor) contains(x, y int) bool {
+	return x >= 1 && x <= e.cols && y >= 1 && y <= e.rows
+}
+
+func ordered(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+
+	return a, b
 }
 
-func (e *Editor) clear() {
-	grid := make([][]string, e.rows)
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
 
-	for j := 0; j < e.rows; j++ {
-		grid[j] = make([]string, e.cols)
-		for k := 0; k < e.cols; k++ {
-			grid[j][k] = "O"
-		}
+	return n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
 	}
 
-	e.Image = grid
+	return 0
 }
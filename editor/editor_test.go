@@ -1,6 +1,14 @@
 package editor_test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"strings"
+
 	"github.com/mo-work/go-technical-test-for-claudia/editor"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -13,7 +21,7 @@ var _ = Describe("Editor", func() {
 
 			e := editor.Editor{}
 			e.CreateImage(2, 2)
-			Expect(e.Image).To(Equal(expected))
+			Expect(pixels(e)).To(Equal(expected))
 		})
 	})
 
@@ -27,7 +35,7 @@ var _ = Describe("Editor", func() {
 		It("sets the given bit to a value", func() {
 			expected := [][]string{{"O", "R"}, {"O", "O"}, {"O", "O"}}
 			Expect(e.Set(2, 1, "R")).To(Succeed())
-			Expect(e.Image).To(Equal(expected))
+			Expect(pixels(e)).To(Equal(expected))
 		})
 
 		Context("if the x coordinate is out of range", func() {
@@ -43,6 +51,13 @@ var _ = Describe("Editor", func() {
 				Expect(err).To(MatchError("given coordinate is beyond image grid"))
 			})
 		})
+
+		Context("if a coordinate is below the first row or column", func() {
+			It("fails", func() {
+				err := e.Set(0, 1, "R")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+			})
+		})
 	})
 
 	Describe("SetMultiY", func() {
@@ -55,14 +70,14 @@ var _ = Describe("Editor", func() {
 		It("sets the given y-axis bits to a value", func() {
 			expected := [][]string{{"O", "G"}, {"O", "G"}, {"O", "G"}}
 			Expect(e.SetMultiY(2, 1, 3, "G")).To(Succeed())
-			Expect(e.Image).To(Equal(expected))
+			Expect(pixels(e)).To(Equal(expected))
 		})
 
 		Context("if y1 is greater than y2", func() {
 			It("a line will still be drawn", func() {
 				expected := [][]string{{"O", "G"}, {"O", "G"}, {"O", "G"}}
 				Expect(e.SetMultiY(2, 3, 1, "G")).To(Succeed())
-				Expect(e.Image).To(Equal(expected))
+				Expect(pixels(e)).To(Equal(expected))
 			})
 		})
 
@@ -84,14 +99,14 @@ var _ = Describe("Editor", func() {
 		It("sets the given x-axis bits to a value", func() {
 			expected := [][]string{{"B", "B", "B"}, {"O", "O", "O"}}
 			Expect(e.SetMultiX(1, 3, 1, "B")).To(Succeed())
-			Expect(e.Image).To(Equal(expected))
+			Expect(pixels(e)).To(Equal(expected))
 		})
 
 		Context("if x1 is greater than x2", func() {
 			It("a line will still be drawn", func() {
 				expected := [][]string{{"B", "B", "B"}, {"O", "O", "O"}}
 				Expect(e.SetMultiX(3, 1, 1, "B")).To(Succeed())
-				Expect(e.Image).To(Equal(expected))
+				Expect(pixels(e)).To(Equal(expected))
 			})
 		})
 
@@ -103,6 +118,297 @@ var _ = Describe("Editor", func() {
 		})
 	})
 
+	Describe("Rect", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(4, 4)
+		})
+
+		It("draws the outline of the rectangle", func() {
+			expected := [][]string{
+				{"R", "R", "R", "O"},
+				{"R", "O", "R", "O"},
+				{"R", "R", "R", "O"},
+				{"O", "O", "O", "O"},
+			}
+			Expect(e.Rect(1, 1, 3, 3, "R")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		Context("if the corners are given in reverse", func() {
+			It("a rectangle will still be drawn", func() {
+				expected := [][]string{
+					{"O", "O", "O", "O"},
+					{"O", "R", "R", "R"},
+					{"O", "R", "O", "R"},
+					{"O", "R", "R", "R"},
+				}
+				Expect(e.Rect(4, 4, 2, 2, "R")).To(Succeed())
+				Expect(pixels(e)).To(Equal(expected))
+			})
+		})
+
+		Context("if a corner is out of range", func() {
+			It("fails", func() {
+				err := e.Rect(2, 2, 5, 3, "R")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+			})
+		})
+
+		It("is undone as a single edit", func() {
+			Expect(e.Rect(1, 1, 3, 3, "R")).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("OOOO\nOOOO\nOOOO\nOOOO\n"))
+		})
+	})
+
+	Describe("Box", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(4, 3)
+		})
+
+		It("fills the rectangle", func() {
+			expected := [][]string{
+				{"O", "O", "O", "O"},
+				{"O", "B", "B", "B"},
+				{"O", "B", "B", "B"},
+			}
+			Expect(e.Box(4, 3, 2, 2, "B")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		Context("if a corner is out of range", func() {
+			It("fails", func() {
+				err := e.Box(0, 1, 2, 2, "B")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+			})
+		})
+
+		Context("if the rectangle reaches far off the grid", func() {
+			It("fills the part on the grid without visiting the rest", func() {
+				err := e.Box(-1<<30, 2, 1<<30, 1<<30, "B")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+				Expect(e.Pretty()).To(Equal("OOOO\nBBBB\nBBBB\n"))
+			})
+		})
+	})
+
+	Describe("Line", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(4, 3)
+		})
+
+		It("draws a diagonal line between the endpoints", func() {
+			expected := [][]string{
+				{"D", "O", "O", "O"},
+				{"O", "D", "O", "O"},
+				{"O", "O", "D", "O"},
+			}
+			Expect(e.Line(1, 1, 3, 3, "D")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		It("rasterises shallow slopes", func() {
+			expected := [][]string{
+				{"D", "D", "O", "O"},
+				{"O", "O", "D", "D"},
+				{"O", "O", "O", "O"},
+			}
+			Expect(e.Line(1, 1, 4, 2, "D")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		Context("if the endpoints are given in reverse", func() {
+			It("draws the same line", func() {
+				expected := [][]string{
+					{"O", "O", "O", "D"},
+					{"O", "D", "D", "O"},
+					{"D", "O", "O", "O"},
+				}
+				Expect(e.Line(4, 1, 1, 3, "D")).To(Succeed())
+				Expect(pixels(e)).To(Equal(expected))
+			})
+		})
+
+		Context("if the line leaves the grid", func() {
+			It("draws the visible part and fails", func() {
+				expected := [][]string{
+					{"O", "O", "O", "O"},
+					{"O", "O", "O", "O"},
+					{"O", "O", "O", "D"},
+				}
+				err := e.Line(4, 3, 6, 5, "D")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+				Expect(pixels(e)).To(Equal(expected))
+			})
+		})
+	})
+
+	Describe("Circle", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(7, 7)
+		})
+
+		It("draws the outline of the circle", func() {
+			e.Circle(4, 4, 3, "A")
+			Expect(e.Pretty()).To(Equal("" +
+				"OOAAAOO\n" +
+				"OAOOOAO\n" +
+				"AOOOOOA\n" +
+				"AOOOOOA\n" +
+				"AOOOOOA\n" +
+				"OAOOOAO\n" +
+				"OOAAAOO\n"))
+		})
+
+		Context("if the circle extends past the grid", func() {
+			It("draws the visible part", func() {
+				e.Circle(1, 1, 2, "A")
+				Expect(e.Pretty()).To(Equal("" +
+					"OOAOOOO\n" +
+					"OOAOOOO\n" +
+					"AAOOOOO\n" +
+					"OOOOOOO\n" +
+					"OOOOOOO\n" +
+					"OOOOOOO\n" +
+					"OOOOOOO\n"))
+			})
+		})
+	})
+
+	Describe("FilledCircle", func() {
+		It("fills the circle", func() {
+			var e editor.Editor
+			e.CreateImage(7, 7)
+			e.FilledCircle(4, 4, 3, "A")
+			Expect(e.Pretty()).To(Equal("" +
+				"OOAAAOO\n" +
+				"OAAAAAO\n" +
+				"AAAAAAA\n" +
+				"AAAAAAA\n" +
+				"AAAAAAA\n" +
+				"OAAAAAO\n" +
+				"OOAAAOO\n"))
+		})
+	})
+
+	Describe("Ellipse", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(9, 5)
+		})
+
+		It("draws the outline of the ellipse", func() {
+			e.Ellipse(5, 3, 4, 2, "E")
+			Expect(e.Pretty()).To(Equal("" +
+				"OOEEEEEOO\n" +
+				"OEOOOOOEO\n" +
+				"EOOOOOOOE\n" +
+				"OEOOOOOEO\n" +
+				"OOEEEEEOO\n"))
+		})
+
+		Context("if the ellipse is flat", func() {
+			It("draws a line", func() {
+				e.Ellipse(5, 3, 2, 0, "E")
+				Expect(e.Pretty()).To(Equal("" +
+					"OOOOOOOOO\n" +
+					"OOOOOOOOO\n" +
+					"OOEEEEEOO\n" +
+					"OOOOOOOOO\n" +
+					"OOOOOOOOO\n"))
+			})
+		})
+
+		Context("if the ellipse extends past the grid", func() {
+			It("draws the visible part", func() {
+				e.Ellipse(9, 3, 4, 2, "E")
+				Expect(e.Pretty()).To(Equal("" +
+					"OOOOOOEEE\n" +
+					"OOOOOEOOO\n" +
+					"OOOOEOOOO\n" +
+					"OOOOOEOOO\n" +
+					"OOOOOOEEE\n"))
+			})
+		})
+	})
+
+	Describe("FilledEllipse", func() {
+		It("fills the ellipse", func() {
+			var e editor.Editor
+			e.CreateImage(9, 5)
+			e.FilledEllipse(5, 3, 4, 2, "E")
+			Expect(e.Pretty()).To(Equal("" +
+				"OOEEEEEOO\n" +
+				"OEEEEEEEO\n" +
+				"EEEEEEEEE\n" +
+				"OEEEEEEEO\n" +
+				"OOEEEEEOO\n"))
+		})
+	})
+
+	Describe("Fill", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(4, 3)
+			e.SetMultiY(2, 1, 2, "W")
+			e.SetMultiX(2, 4, 2, "W")
+		})
+
+		It("recolours the connected region of the same colour", func() {
+			expected := [][]string{
+				{"A", "W", "O", "O"},
+				{"A", "W", "W", "W"},
+				{"A", "A", "A", "A"},
+			}
+			Expect(e.Fill(1, 1, "A")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		It("does not cross diagonal gaps", func() {
+			expected := [][]string{
+				{"O", "W", "B", "B"},
+				{"O", "W", "W", "W"},
+				{"O", "O", "O", "O"},
+			}
+			Expect(e.Fill(4, 1, "B")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		It("leaves the grid alone when the region already has the colour", func() {
+			expected := [][]string{
+				{"O", "W", "O", "O"},
+				{"O", "W", "W", "W"},
+				{"O", "O", "O", "O"},
+			}
+			Expect(e.Fill(2, 2, "W")).To(Succeed())
+			Expect(pixels(e)).To(Equal(expected))
+		})
+
+		It("copes with the largest image size", func() {
+			e.CreateImage(1024, 1024)
+			Expect(e.Fill(512, 512, "Z")).To(Succeed())
+			Expect(pixels(e)[0][0]).To(Equal("Z"))
+			Expect(pixels(e)[1023][1023]).To(Equal("Z"))
+		})
+
+		Context("if the coordinate is out of range", func() {
+			It("fails", func() {
+				err := e.Fill(5, 1, "A")
+				Expect(err).To(MatchError("given coordinate is beyond image grid"))
+			})
+		})
+	})
+
 	Describe("Clear", func() {
 		var e editor.Editor
 
@@ -113,19 +419,1056 @@ var _ = Describe("Editor", func() {
 
 		It("resets the grid to 'blank'", func() {
 			set := [][]string{{"O", "C"}, {"O", "O"}, {"O", "O"}}
-			Expect(e.Image).To(Equal(set))
+			Expect(pixels(e)).To(Equal(set))
 
 			cleared := [][]string{{"O", "O"}, {"O", "O"}, {"O", "O"}}
 			e.Clear()
-			Expect(e.Image).To(Equal(cleared))
+			Expect(pixels(e)).To(Equal(cleared))
 		})
 	})
 
-	Describe("Pretty", func() {
-		It("prettifies the grid for printing", func() {
-			var e editor.Editor
+	Describe("Undo", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
 			e.CreateImage(3, 2)
+		})
+
+		It("reverts the last edit", func() {
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.SetMultiX(1, 3, 2, "B")).To(Succeed())
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"A", "O", "O"}, {"O", "O", "O"}}))
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"O", "O", "O"}, {"O", "O", "O"}}))
+		})
+
+		It("reverts a clear", func() {
+			Expect(e.SetMultiY(2, 1, 2, "G")).To(Succeed())
+			e.Clear()
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"O", "G", "O"}, {"O", "G", "O"}}))
+		})
+
+		It("reverts a fill", func() {
+			Expect(e.Set(2, 1, "W")).To(Succeed())
+			Expect(e.Fill(1, 1, "Z")).To(Succeed())
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"O", "W", "O"}, {"O", "O", "O"}}))
+		})
+
+		It("reverts a fill across many tiles", func() {
+			e.CreateImage(200, 200)
+			Expect(e.Set(100, 100, "A")).To(Succeed())
+			Expect(e.Fill(1, 1, "B")).To(Succeed())
+
+			Expect(e.Undo()).To(Succeed())
+			var out strings.Builder
+			Expect(e.RenderRegion(&out, 99, 100, 101, 100, false, 0)).To(Succeed())
+			Expect(e.RenderRegion(&out, 200, 200, 200, 200, false, 0)).To(Succeed())
+			Expect(out.String()).To(Equal("OAO\nO\n"))
+
+			Expect(e.Redo()).To(Succeed())
+			out.Reset()
+			Expect(e.RenderRegion(&out, 99, 100, 101, 100, false, 0)).To(Succeed())
+			Expect(e.RenderRegion(&out, 200, 200, 200, 200, false, 0)).To(Succeed())
+			Expect(out.String()).To(Equal("BAB\nB\n"))
+		})
+
+		It("reverts edits made after undoing a reshape", func() {
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			e.FlipHorizontal()
+			Expect(e.Pretty()).To(Equal("OOA\nOOO\n"))
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Set(2, 2, "B")).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AOO\nOBO\n"))
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AOO\nOOO\n"))
+			Expect(e.Undo()).To(Succeed())
 			Expect(e.Pretty()).To(Equal("OOO\nOOO\n"))
+
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AOO\nOBO\n"))
+			Expect(e.Redo()).To(MatchError("nothing to redo"))
+		})
+
+		It("only remembers a bounded number of edits", func() {
+			for i := 0; i < 101; i++ {
+				Expect(e.Set(1, 1, []string{"A", "B"}[i%2])).To(Succeed())
+			}
+
+			for i := 0; i < 100; i++ {
+				Expect(e.Undo()).To(Succeed())
+			}
+			Expect(pixels(e)[0][0]).To(Equal("A"))
+			Expect(e.Undo()).To(MatchError("nothing to undo"))
+		})
+
+		Context("if there is nothing to undo", func() {
+			It("fails", func() {
+				Expect(e.Undo()).To(MatchError("nothing to undo"))
+			})
+		})
+
+		Context("if the edit changed nothing", func() {
+			It("is not recorded", func() {
+				Expect(e.Set(1, 1, "O")).To(Succeed())
+				Expect(e.Undo()).To(MatchError("nothing to undo"))
+			})
 		})
 	})
-})
+
+	Describe("Redo", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(3, 2)
+			Expect(e.SetMultiX(1, 3, 1, "B")).To(Succeed())
+		})
+
+		It("reapplies the last undone edit", func() {
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Redo()).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"B", "B", "B"}, {"O", "O", "O"}}))
+		})
+
+		Context("if a new edit is made after undoing", func() {
+			It("fails", func() {
+				Expect(e.Undo()).To(Succeed())
+				Expect(e.Set(1, 2, "C")).To(Succeed())
+				Expect(e.Redo()).To(MatchError("nothing to redo"))
+			})
+		})
+
+		Context("if there is nothing to redo", func() {
+			It("fails", func() {
+				Expect(e.Redo()).To(MatchError("nothing to redo"))
+			})
+		})
+	})
+
+	Describe("Copy and Paste", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(4, 3)
+			e.Set(1, 1, "A")
+			e.Set(2, 1, "B")
+			e.Set(1, 2, "C")
+		})
+
+		It("pastes the copied region at the new origin", func() {
+			Expect(e.Copy(2, 2, 1, 1)).To(Succeed())
+			Expect(e.Paste(3, 2)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("ABOO\nCOAB\nOOCO\n"))
+		})
+
+		It("keeps the copy when the image changes", func() {
+			Expect(e.Copy(1, 1, 2, 1)).To(Succeed())
+			e.Clear()
+			Expect(e.Paste(2, 3)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OOOO\nOOOO\nOABO\n"))
+		})
+
+		It("clips the paste at the grid edge", func() {
+			Expect(e.Copy(1, 1, 2, 2)).To(Succeed())
+			Expect(e.Paste(4, 3)).To(Succeed())
+			Expect(e.Paste(0, 0)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OBOO\nCOOO\nOOOA\n"))
+		})
+
+		It("pastes over the region it was copied from", func() {
+			Expect(e.Copy(1, 1, 2, 2)).To(Succeed())
+			Expect(e.Paste(2, 2)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("ABOO\nCABO\nOCOO\n"))
+		})
+
+		It("undoes a paste in one step", func() {
+			Expect(e.Copy(1, 1, 2, 2)).To(Succeed())
+			Expect(e.Paste(3, 2)).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("ABOO\nCOOO\nOOOO\n"))
+		})
+
+		Context("if the region is out of range", func() {
+			It("fails", func() {
+				Expect(e.Copy(1, 1, 5, 2)).To(MatchError(editor.ErrOutOfBounds))
+			})
+		})
+
+		Context("if nothing has been copied", func() {
+			It("fails", func() {
+				Expect(e.Paste(1, 1)).To(MatchError("nothing to paste"))
+			})
+		})
+	})
+
+	Describe("Picture and PastePicture", func() {
+		It("copies one image into another, clipped at the edge", func() {
+			icon := editor.Editor{}
+			icon.CreateImage(2, 2)
+			icon.Set(1, 1, "A")
+			icon.Set(2, 2, "B")
+
+			sheet := editor.Editor{}
+			sheet.CreateImage(3, 2)
+			sheet.Set(3, 2, "C")
+			Expect(sheet.PastePicture(icon.Picture(), 2, 2)).To(Succeed())
+
+			Expect(sheet.Pretty()).To(Equal("OOO\nOAO\n"))
+			Expect(sheet.Undo()).To(Succeed())
+			Expect(sheet.Pretty()).To(Equal("OOO\nOOC\n"))
+		})
+
+		It("combines the visible layers", func() {
+			icon := editor.Editor{}
+			icon.CreateImage(2, 1)
+			icon.Set(1, 1, "A")
+			Expect(icon.AddLayer("top")).To(Succeed())
+			icon.Set(2, 1, "B")
+
+			sheet := editor.Editor{}
+			sheet.CreateImage(2, 1)
+			Expect(sheet.PastePicture(icon.Picture(), 1, 1)).To(Succeed())
+
+			Expect(sheet.Pretty()).To(Equal("AB\n"))
+		})
+
+		It("gives a copy that later edits leave alone", func() {
+			e := editor.Editor{}
+			e.CreateImage(1, 1)
+			picture := e.Picture()
+			e.Set(1, 1, "A")
+			Expect(e.PastePicture(picture, 1, 1)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("O\n"))
+		})
+
+		It("copies a large, mostly blank image quickly", func() {
+			big := editor.Editor{}
+			big.CreateImage(65536, 65536)
+			big.Set(65536, 1, "A")
+
+			e := editor.Editor{}
+			e.CreateImage(65536, 65536)
+			e.Set(2, 2, "B")
+			Expect(e.PastePicture(big.Picture(), 1, 1)).To(Succeed())
+
+			Expect(e.RenderRegion(ioutil.Discard, 1, 1, 1, 1, false, 0)).To(Succeed())
+			var out strings.Builder
+			Expect(e.RenderRegion(&out, 65535, 1, 65536, 2, false, 0)).To(Succeed())
+			Expect(out.String()).To(Equal("OA\nOO\n"))
+			out.Reset()
+			Expect(e.RenderRegion(&out, 1, 1, 2, 2, false, 0)).To(Succeed())
+			Expect(out.String()).To(Equal("OO\nOO\n"))
+		})
+
+		Context("if given something other than a picture", func() {
+			It("fails", func() {
+				e := editor.Editor{}
+				e.CreateImage(1, 1)
+
+				Expect(e.PastePicture("A", 1, 1)).To(MatchError("not a picture from an editor"))
+			})
+		})
+	})
+
+	Describe("Move", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(4, 3)
+			e.Set(1, 1, "A")
+			e.Set(2, 1, "B")
+			e.Set(1, 2, "C")
+		})
+
+		It("moves the region and leaves white behind", func() {
+			Expect(e.Move(1, 1, 2, 2, 2, 1)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OOOO\nOOAB\nOOCO\n"))
+		})
+
+		It("handles a destination overlapping the source", func() {
+			Expect(e.Move(1, 1, 2, 2, 1, 0)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OABO\nOCOO\nOOOO\n"))
+		})
+
+		It("loses any part moved off the grid", func() {
+			Expect(e.Move(1, 1, 2, 2, -1, 2)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OOOO\nOOOO\nBOOO\n"))
+		})
+
+		It("leaves the clipboard alone", func() {
+			Expect(e.Move(1, 1, 2, 2, 2, 1)).To(Succeed())
+			Expect(e.Paste(1, 1)).To(MatchError("nothing to paste"))
+		})
+
+		It("undoes a move in one step", func() {
+			Expect(e.Move(1, 1, 2, 2, 1, 1)).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("ABOO\nCOOO\nOOOO\n"))
+		})
+
+		Context("if the region is out of range", func() {
+			It("fails", func() {
+				Expect(e.Move(0, 1, 2, 2, 1, 1)).To(MatchError(editor.ErrOutOfBounds))
+				Expect(e.Pretty()).To(Equal("ABOO\nCOOO\nOOOO\n"))
+			})
+		})
+	})
+
+	Describe("transforms", func() {
+		var e editor.Editor
+
+		// The image starts as
+		//   ABC
+		//   DOO
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(3, 2)
+			e.SetMultiX(1, 3, 1, "A")
+			e.Set(2, 1, "B")
+			e.Set(3, 1, "C")
+			e.Set(1, 2, "D")
+		})
+
+		Describe("Rotate", func() {
+			It("turns the image a quarter clockwise, swapping its width and height", func() {
+				Expect(e.Rotate(90)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("DA\nOB\nOC\n"))
+				cols, rows := e.Size()
+				Expect(cols).To(Equal(2))
+				Expect(rows).To(Equal(3))
+			})
+
+			It("turns the image half way round", func() {
+				Expect(e.Rotate(180)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("OOD\nCBA\n"))
+			})
+
+			It("turns the image three quarters clockwise", func() {
+				Expect(e.Rotate(270)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("CO\nBO\nAD\n"))
+			})
+
+			It("treats negative angles as anticlockwise", func() {
+				Expect(e.Rotate(-90)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("CO\nBO\nAD\n"))
+			})
+
+			It("checks later edits against the new shape", func() {
+				Expect(e.Rotate(90)).To(Succeed())
+
+				Expect(e.Set(2, 3, "Z")).To(Succeed())
+				Expect(e.Set(3, 1, "Z")).To(MatchError(editor.ErrOutOfBounds))
+				Expect(e.Pretty()).To(Equal("DA\nOB\nOZ\n"))
+			})
+
+			Context("if the angle is not a multiple of 90", func() {
+				It("fails", func() {
+					Expect(e.Rotate(45)).To(MatchError("rotation must be a multiple of 90 degrees"))
+					Expect(e.Pretty()).To(Equal("ABC\nDOO\n"))
+				})
+			})
+		})
+
+		Describe("FlipHorizontal", func() {
+			It("mirrors the image left to right", func() {
+				e.FlipHorizontal()
+
+				Expect(e.Pretty()).To(Equal("CBA\nOOD\n"))
+			})
+		})
+
+		Describe("FlipVertical", func() {
+			It("mirrors the image top to bottom", func() {
+				e.FlipVertical()
+
+				Expect(e.Pretty()).To(Equal("DOO\nABC\n"))
+			})
+		})
+
+		Describe("Transpose", func() {
+			It("swaps rows and columns", func() {
+				e.Transpose()
+
+				Expect(e.Pretty()).To(Equal("AD\nBO\nCO\n"))
+				cols, rows := e.Size()
+				Expect(cols).To(Equal(2))
+				Expect(rows).To(Equal(3))
+			})
+		})
+
+		Describe("Resize", func() {
+			It("pads the image with white, keeping its pixels", func() {
+				e.Resize(4, 3, 0, 0)
+
+				Expect(e.Pretty()).To(Equal("ABCO\nDOOO\nOOOO\n"))
+				cols, rows := e.Size()
+				Expect(cols).To(Equal(4))
+				Expect(rows).To(Equal(3))
+			})
+
+			It("shifts the pixels by the given offset", func() {
+				e.Resize(5, 4, 1, 1)
+
+				Expect(e.Pretty()).To(Equal("OOOOO\nOABCO\nODOOO\nOOOOO\n"))
+			})
+
+			It("crops pixels that no longer fit", func() {
+				e.Resize(2, 1, -1, 0)
+
+				Expect(e.Pretty()).To(Equal("BC\n"))
+			})
+		})
+
+		Describe("Scale", func() {
+			It("enlarges each pixel", func() {
+				e.Scale(6, 4)
+
+				Expect(e.Pretty()).To(Equal("AABBCC\nAABBCC\nDDOOOO\nDDOOOO\n"))
+			})
+
+			It("shrinks by taking the nearest pixel", func() {
+				e.Scale(6, 4)
+				e.Scale(3, 1)
+
+				Expect(e.Pretty()).To(Equal("ABC\n"))
+			})
+		})
+
+		Describe("Crop", func() {
+			It("cuts the image down to the region", func() {
+				Expect(e.Crop(3, 2, 2, 1)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("BC\nOO\n"))
+			})
+
+			It("checks later edits against the new shape", func() {
+				Expect(e.Crop(1, 1, 2, 1)).To(Succeed())
+
+				Expect(e.Set(2, 1, "Z")).To(Succeed())
+				Expect(e.Set(3, 1, "Z")).To(MatchError(editor.ErrOutOfBounds))
+				Expect(e.Set(1, 2, "Z")).To(MatchError(editor.ErrOutOfBounds))
+			})
+
+			Context("if the region is out of range", func() {
+				It("fails", func() {
+					Expect(e.Crop(1, 1, 4, 2)).To(MatchError(editor.ErrOutOfBounds))
+					Expect(e.Pretty()).To(Equal("ABC\nDOO\n"))
+				})
+			})
+		})
+
+		Describe("CropToContent", func() {
+			It("cuts the image down to the pixels that are not white", func() {
+				e.Resize(6, 5, 2, 1)
+				Expect(e.CropToContent()).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("ABC\nDOO\n"))
+			})
+
+			It("keeps white pixels inside the content", func() {
+				e.Clear()
+				e.Set(2, 1, "A")
+				e.Set(3, 2, "B")
+				Expect(e.CropToContent()).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("AO\nOB\n"))
+			})
+
+			Context("if the image is blank", func() {
+				It("fails", func() {
+					e.Clear()
+					Expect(e.CropToContent()).To(MatchError("nothing to crop to, the image is blank"))
+				})
+			})
+		})
+
+		It("undoes and redoes a change of shape", func() {
+			Expect(e.Rotate(90)).To(Succeed())
+			Expect(e.Set(1, 3, "Z")).To(Succeed())
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("ABC\nDOO\n"))
+			cols, rows := e.Size()
+			Expect(cols).To(Equal(3))
+			Expect(rows).To(Equal(2))
+
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("DA\nOB\nZC\n"))
+		})
+	})
+
+	Describe("layers", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(3, 2)
+			e.SetMultiX(1, 3, 1, "G")
+		})
+
+		It("draws on a new layer over the base", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(2, 1, "A")).To(Succeed())
+			Expect(e.Set(3, 2, "B")).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("GAG\nOOB\n"))
+		})
+
+		It("lets the layers beneath show through transparent pixels", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Box(1, 1, 3, 2, "A")).To(Succeed())
+			Expect(e.Set(2, 1, editor.Transparent)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("AGA\nAAA\n"))
+		})
+
+		It("clears only the layer being drawn on", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(2, 2, "A")).To(Succeed())
+			e.Clear()
+
+			Expect(e.Pretty()).To(Equal("GGG\nOOO\n"))
+		})
+
+		It("draws on the selected layer", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.SelectLayer("base")).To(Succeed())
+			Expect(e.Set(1, 1, "B")).To(Succeed())
+			Expect(e.Set(2, 2, "B")).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("AGG\nOBO\n"))
+		})
+
+		It("leaves hidden layers out", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.HideLayer("notes", true)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("GGG\nOOO\n"))
+
+			Expect(e.HideLayer("notes", false)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AGG\nOOO\n"))
+		})
+
+		It("reorders layers", func() {
+			Expect(e.AddLayer("one")).To(Succeed())
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.AddLayer("two")).To(Succeed())
+			Expect(e.Set(1, 1, "B")).To(Succeed())
+			Expect(e.Pretty()).To(Equal("BGG\nOOO\n"))
+
+			Expect(e.MoveLayer("two", 2)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AGG\nOOO\n"))
+
+			Expect(e.Set(2, 1, "C")).To(Succeed())
+			Expect(e.HideLayer("one", true)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("BCG\nOOO\n"))
+		})
+
+		It("merges a layer into the one beneath", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+			Expect(e.MergeLayer("notes")).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("GGG\nAOO\n"))
+			Expect(e.SelectLayer("notes")).To(MatchError("no layer named 'notes'"))
+			e.Clear()
+			Expect(e.Pretty()).To(Equal("OOO\nOOO\n"))
+		})
+
+		It("transforms every layer", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+			Expect(e.Rotate(90)).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("AG\nOG\nOG\n"))
+			Expect(e.SelectLayer("base")).To(Succeed())
+			Expect(e.Set(1, 3, "B")).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AG\nOG\nBG\n"))
+		})
+
+		It("undoes edits on the layer they were made on", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+			Expect(e.SelectLayer("base")).To(Succeed())
+
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("GGG\nOOO\n"))
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.SelectLayer("notes")).To(MatchError("no layer named 'notes'"))
+
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Redo()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("GGG\nAOO\n"))
+		})
+
+		It("undoes a merge", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+			Expect(e.MergeLayer("notes")).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+
+			Expect(e.HideLayer("notes", true)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("GGG\nOOO\n"))
+		})
+
+		It("redoes a merge", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+			Expect(e.MergeLayer("notes")).To(Succeed())
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.Redo()).To(Succeed())
+
+			Expect(e.SelectLayer("notes")).To(MatchError("no layer named 'notes'"))
+			Expect(e.Pretty()).To(Equal("GGG\nAOO\n"))
+			Expect(e.Undo()).To(Succeed())
+			Expect(e.HideLayer("notes", true)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("GGG\nOOO\n"))
+		})
+
+		It("exports the combined layers", func() {
+			Expect(e.AddLayer("notes")).To(Succeed())
+			Expect(e.Set(1, 2, "A")).To(Succeed())
+
+			buf := &bytes.Buffer{}
+			Expect(e.Save(buf)).To(Succeed())
+			Expect(buf.String()).To(Equal("BITMAP 1\n3 2\nGGG\nAOO\n"))
+		})
+
+		Context("if a layer name is taken", func() {
+			It("fails", func() {
+				Expect(e.AddLayer("base")).To(MatchError("layer 'base' already exists"))
+			})
+		})
+
+		Context("if a layer does not exist", func() {
+			It("fails", func() {
+				Expect(e.SelectLayer("nope")).To(MatchError("no layer named 'nope'"))
+				Expect(e.HideLayer("nope", true)).To(MatchError("no layer named 'nope'"))
+				Expect(e.MoveLayer("nope", 1)).To(MatchError("no layer named 'nope'"))
+				Expect(e.MergeLayer("nope")).To(MatchError("no layer named 'nope'"))
+			})
+		})
+
+		Context("if a layer is moved out of the stack", func() {
+			It("fails", func() {
+				Expect(e.MoveLayer("base", 2)).To(MatchError("layer position must be between 1 and 1"))
+			})
+		})
+
+		Context("if the bottom layer is merged", func() {
+			It("fails", func() {
+				Expect(e.MergeLayer("base")).To(MatchError("layer 'base' has no layer beneath to merge into"))
+			})
+		})
+
+		Context("if no image was ever created", func() {
+			It("gives a resized image a base layer to draw on", func() {
+				var e editor.Editor
+				e.Resize(3, 3, 0, 0)
+				Expect(e.Set(1, 1, "A")).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("AOO\nOOO\nOOO\n"))
+			})
+
+			It("gives a scaled image a base layer to draw on", func() {
+				var e editor.Editor
+				e.Scale(5, 5)
+				Expect(e.Set(1, 1, "A")).To(Succeed())
+				Expect(e.MergeLayer("base")).To(MatchError("layer 'base' has no layer beneath to merge into"))
+			})
+
+			It("puts a new layer over a base layer", func() {
+				var e editor.Editor
+				Expect(e.AddLayer("notes")).To(Succeed())
+				e.Resize(2, 1, 0, 0)
+				Expect(e.Set(1, 1, "A")).To(Succeed())
+				Expect(e.MergeLayer("notes")).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal("AO\n"))
+			})
+		})
+	})
+
+	Describe("Palette", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(3, 2)
+		})
+
+		It("resolves colours given by name, whatever their case", func() {
+			Expect(e.Set(1, 1, "red")).To(Succeed())
+			Expect(e.SetMultiX(1, 3, 2, "Grey")).To(Succeed())
+			Expect(e.Pretty()).To(Equal("ROO\nAAA\n"))
+		})
+
+		It("rejects colours that are not in the palette, changing nothing", func() {
+			for _, colour := range []string{"RED!", "#", "r", ""} {
+				Expect(e.Set(1, 1, colour)).To(MatchError(fmt.Sprintf("unknown colour '%s'", colour)))
+			}
+
+			err := e.Box(1, 1, 2, 2, "AB")
+			Expect(errors.Is(err, editor.ErrUnknownColour)).To(BeTrue())
+			Expect(e.SetMultiY(1, 1, 2, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.Line(1, 1, 3, 2, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.Rect(1, 1, 3, 2, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.Circle(2, 1, 1, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.FilledCircle(2, 1, 1, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.Ellipse(2, 1, 1, 1, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.FilledEllipse(2, 1, 1, 1, "?")).To(MatchError("unknown colour '?'"))
+			Expect(e.Fill(1, 1, "?")).To(MatchError("unknown colour '?'"))
+
+			Expect(e.Pretty()).To(Equal("OOO\nOOO\n"))
+			Expect(e.Undo()).To(MatchError("nothing to undo"))
+		})
+
+		It("uses the editor's own palette and background", func() {
+			e.Palette = &editor.Palette{Symbols: "#-", Names: map[string]string{"wall": "#"}, Background: "-"}
+			e.CreateImage(3, 2)
+
+			Expect(e.Set(2, 1, "WALL")).To(Succeed())
+			Expect(e.Set(3, 1, "A")).To(MatchError("unknown colour 'A'"))
+			Expect(e.Pretty()).To(Equal("-#-\n---\n"))
+
+			Expect(e.CropToContent()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("#\n"))
+		})
+
+		It("falls back to the default background, and ignores empty names", func() {
+			e.Palette = &editor.Palette{Symbols: "#O", Names: map[string]string{"nothing": ""}}
+			e.CreateImage(2, 1)
+
+			Expect(e.Set(1, 1, "nothing")).To(MatchError("unknown colour 'nothing'"))
+			Expect(e.Set(2, 1, "#")).To(Succeed())
+			Expect(e.Pretty()).To(Equal("O#\n"))
+		})
+
+		It("names the background white", func() {
+			e.CreateImage(2, 1)
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.Set(1, 1, "white")).To(Succeed())
+
+			Expect(e.Pretty()).To(Equal("OO\n"))
+			Expect(e.CropToContent()).To(MatchError("nothing to crop to, the image is blank"))
+		})
+
+		It("loads what it saves when the background is not one of its symbols", func() {
+			for _, palette := range []*editor.Palette{{Symbols: "#", Background: "-"}, {Symbols: "#"}} {
+				e.Palette = palette
+				e.CreateImage(2, 1)
+				Expect(e.Set(2, 1, "#")).To(Succeed())
+
+				var buf bytes.Buffer
+				Expect(e.Save(&buf)).To(Succeed())
+				saved := buf.String()
+				Expect(e.Load(&buf)).To(Succeed())
+
+				Expect(e.Pretty()).To(Equal(strings.TrimPrefix(saved, "BITMAP 1\n2 1\n")))
+			}
+		})
+
+		It("refuses to load a file with colours not in the palette", func() {
+			err := e.Load(strings.NewReader("BITMAP 1\n2 1\nO?\n"))
+			Expect(err).To(MatchError("bitmap file row 1: unknown colour '?'"))
+			Expect(errors.Is(err, editor.ErrUnknownColour)).To(BeTrue())
+		})
+	})
+
+	Describe("large images", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(65536, 65536)
+		})
+
+		It("edits a mostly empty image of the largest size", func() {
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.Set(65536, 65536, "B")).To(Succeed())
+			Expect(e.Rect(40000, 30000, 40100, 30100, "C")).To(Succeed())
+
+			e.Clear()
+			Expect(e.Undo()).To(Succeed())
+
+			Expect(e.Rotate(90)).To(Succeed())
+			Expect(e.Copy(65536, 1, 65536, 1)).To(Succeed())
+			e.CreateImage(1, 1)
+			Expect(e.Paste(1, 1)).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"A"}}))
+		})
+
+		It("copies and moves large regions by their tiles", func() {
+			Expect(e.Set(100, 100, "A")).To(Succeed())
+			Expect(e.Move(1, 1, 65536, 65536, 1, 2)).To(Succeed())
+			Expect(e.Copy(1, 1, 65536, 65536)).To(Succeed())
+			Expect(e.Paste(3, 1)).To(Succeed())
+
+			Expect(e.Crop(100, 100, 104, 102)).To(Succeed())
+			Expect(e.Pretty()).To(Equal("OOOOO\nOOOOO\nOOOAO\n"))
+		})
+
+		It("crops to content by searching only the tiles drawn on", func() {
+			Expect(e.Set(30000, 20000, "A")).To(Succeed())
+			Expect(e.Set(30002, 20001, "B")).To(Succeed())
+
+			Expect(e.CropToContent()).To(Succeed())
+			Expect(e.Pretty()).To(Equal("AOO\nOOB\n"))
+		})
+
+		It("keeps pixels across tile edges when transformed", func() {
+			e.CreateImage(130, 70)
+			Expect(e.Set(64, 64, "A")).To(Succeed())
+			Expect(e.Set(65, 65, "B")).To(Succeed())
+			Expect(e.Set(130, 70, "C")).To(Succeed())
+
+			e.FlipHorizontal()
+			Expect(e.Rotate(180)).To(Succeed())
+			grid := pixels(e)
+			Expect(grid[70-64][64-1]).To(Equal("A"))
+			Expect(grid[70-65][65-1]).To(Equal("B"))
+			Expect(grid[0][129]).To(Equal("C"))
+		})
+	})
+
+	Describe("Save", func() {
+		It("writes a header, the dimensions and the pixels", func() {
+			var e editor.Editor
+			e.CreateImage(3, 2)
+			Expect(e.Set(2, 2, "A")).To(Succeed())
+
+			buf := &bytes.Buffer{}
+			Expect(e.Save(buf)).To(Succeed())
+			Expect(buf.String()).To(Equal("BITMAP 1\n3 2\nOOO\nOAO\n"))
+		})
+	})
+
+	Describe("Load", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(2, 2)
+		})
+
+		It("replaces the image with the saved one", func() {
+			Expect(e.Load(strings.NewReader("BITMAP 1\n3 2\nOOO\nOAO\n"))).To(Succeed())
+			Expect(pixels(e)).To(Equal([][]string{{"O", "O", "O"}, {"O", "A", "O"}}))
+
+			Expect(e.Set(3, 2, "B")).To(Succeed())
+			Expect(e.Set(4, 2, "B")).To(MatchError("given coordinate is beyond image grid"))
+		})
+
+		It("starts a fresh history", func() {
+			Expect(e.Set(1, 1, "A")).To(Succeed())
+			Expect(e.Load(strings.NewReader("BITMAP 1\n1 1\nO\n"))).To(Succeed())
+			Expect(e.Undo()).To(MatchError("nothing to undo"))
+		})
+
+		Context("if the header is missing", func() {
+			It("fails", func() {
+				err := e.Load(strings.NewReader("3 2\nOOO\nOAO\n"))
+				Expect(err).To(MatchError("not a bitmap file"))
+			})
+		})
+
+		Context("if the version is not supported", func() {
+			It("fails", func() {
+				err := e.Load(strings.NewReader("BITMAP 9\n3 2\nOOO\nOAO\n"))
+				Expect(err).To(MatchError("unsupported bitmap file version 9"))
+			})
+		})
+
+		Context("if a row does not match the dimensions", func() {
+			It("fails and keeps the current image", func() {
+				err := e.Load(strings.NewReader("BITMAP 1\n3 2\nOOO\nOA\n"))
+				Expect(err).To(MatchError("bitmap file row 2 has 2 pixels, expected 3"))
+				Expect(pixels(e)).To(Equal([][]string{{"O", "O"}, {"O", "O"}}))
+			})
+		})
+
+		Context("if rows are missing", func() {
+			It("fails", func() {
+				err := e.Load(strings.NewReader("BITMAP 1\n3 2\nOOO\n"))
+				Expect(err).To(MatchError("bitmap file has fewer rows than its dimensions"))
+			})
+		})
+	})
+
+	Describe("PNG", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e = editor.Editor{}
+			e.CreateImage(2, 1)
+			Expect(e.Set(2, 1, "R")).To(Succeed())
+		})
+
+		It("draws each pixel as a scaled square using the default palette", func() {
+			buf := &bytes.Buffer{}
+			Expect(e.PNG(buf, 3)).To(Succeed())
+
+			img, err := png.Decode(buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(img.Bounds().Dx()).To(Equal(6))
+			Expect(img.Bounds().Dy()).To(Equal(3))
+			Expect(color.RGBAModel.Convert(img.At(2, 2))).To(Equal(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+			Expect(color.RGBAModel.Convert(img.At(3, 0))).To(Equal(color.RGBA{R: 0xff, A: 0xff}))
+		})
+
+		It("prefers the editor's own palette", func() {
+			e.RGB = editor.RGBPalette{"R": {R: 0x12, G: 0x34, B: 0x56, A: 0xff}}
+
+			buf := &bytes.Buffer{}
+			Expect(e.PNG(buf, 1)).To(Succeed())
+
+			img, err := png.Decode(buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(color.RGBAModel.Convert(img.At(0, 0))).To(Equal(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+			Expect(color.RGBAModel.Convert(img.At(1, 0))).To(Equal(color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}))
+		})
+
+		Context("if the scale is less than 1", func() {
+			It("fails", func() {
+				Expect(e.PNG(&bytes.Buffer{}, 0)).To(MatchError("png scale must be at least 1"))
+			})
+		})
+
+		Context("if the scaled image would be too large", func() {
+			It("fails without allocating it", func() {
+				Expect(e.PNG(&bytes.Buffer{}, 100000000)).To(MatchError("png at scale 100000000 would be larger than 8192 x 8192 pixels"))
+				Expect(e.PNG(&bytes.Buffer{}, 4097)).To(HaveOccurred())
+				Expect(e.PNG(&bytes.Buffer{}, 4096)).To(Succeed())
+			})
+		})
+	})
+
+	Describe("Pretty", func() {
+		It("prettifies the grid for printing", func() {
+			var e editor.Editor
+			e.CreateImage(3, 2)
+			Expect(e.Pretty()).To(Equal("OOO\nOOO\n"))
+		})
+	})
+
+	Describe("Render", func() {
+		It("writes the visible layers a row at a time", func() {
+			var e editor.Editor
+			e.CreateImage(3, 2)
+			Expect(e.Set(2, 1, "A")).To(Succeed())
+			Expect(e.AddLayer("top")).To(Succeed())
+			Expect(e.Set(3, 2, "B")).To(Succeed())
+
+			buf := &bytes.Buffer{}
+			Expect(e.Render(buf)).To(Succeed())
+			Expect(buf.String()).To(Equal("OAO\nOOB\n"))
+		})
+
+		It("reports a failure to write", func() {
+			var e editor.Editor
+			e.CreateImage(3, 2)
+			Expect(e.Render(failingWriter{})).To(MatchError("disk full"))
+		})
+	})
+
+	Describe("RenderRegion", func() {
+		var e editor.Editor
+
+		BeforeEach(func() {
+			e.CreateImage(12, 10)
+			Expect(e.Set(9, 8, "A")).To(Succeed())
+			Expect(e.Set(11, 10, "B")).To(Succeed())
+		})
+
+		It("writes only the given rectangle", func() {
+			buf := &bytes.Buffer{}
+			Expect(e.RenderRegion(buf, 12, 10, 9, 8, false, 0)).To(Succeed())
+			Expect(buf.String()).To(Equal("AOOO\nOOOO\nOOBO\n"))
+		})
+
+		It("numbers the columns and rows along the edges", func() {
+			buf := &bytes.Buffer{}
+			Expect(e.RenderRegion(buf, 8, 8, 12, 10, true, 0)).To(Succeed())
+			Expect(buf.String()).To(Equal("" +
+				"     111\n" +
+				"   89012\n" +
+				" 8 OAOOO\n" +
+				" 9 OOOOO\n" +
+				"10 OOOBO\n"))
+		})
+
+		It("draws pixels as blocks of truecolor, setting the colour only where it changes", func() {
+			buf := &bytes.Buffer{}
+			Expect(e.RenderRegion(buf, 8, 8, 10, 8, false, editor.TrueColour)).To(Succeed())
+			Expect(buf.String()).To(Equal("" +
+				"\x1b[48;2;255;255;255m  \x1b[48;2;128;128;128m  \x1b[48;2;255;255;255m  \x1b[0m\n"))
+		})
+
+		It("draws pixels as blocks of the nearest of 256 colours, using the editor's palette", func() {
+			e.RGB = editor.RGBPalette{"A": {R: 0xff, G: 0x80, A: 0xff}}
+
+			buf := &bytes.Buffer{}
+			Expect(e.RenderRegion(buf, 8, 8, 9, 8, false, editor.ANSI256)).To(Succeed())
+			Expect(buf.String()).To(Equal("\x1b[48;5;231m  \x1b[48;5;208m  \x1b[0m\n"))
+		})
+
+		It("lines the rulers up with blocks", func() {
+			buf := &bytes.Buffer{}
+			Expect(e.RenderRegion(buf, 9, 9, 10, 9, true, editor.ANSI256)).To(Succeed())
+			Expect(buf.String()).To(Equal("" +
+				"    1\n" +
+				"  9 0\n" +
+				"9 \x1b[48;5;231m    \x1b[0m\n"))
+		})
+
+		Context("if the rectangle reaches off the grid", func() {
+			It("fails without writing anything", func() {
+				buf := &bytes.Buffer{}
+				Expect(e.RenderRegion(buf, 1, 1, 13, 2, false, 0)).To(MatchError("given coordinate is beyond image grid"))
+				Expect(buf.Len()).To(Equal(0))
+			})
+		})
+	})
+})
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// pixels reads an image back as a grid indexed by row and then column.
+func pixels(e editor.Editor) [][]string {
+	grid := [][]string{}
+	for _, row := range strings.Split(strings.TrimSuffix(e.Pretty(), "\n"), "\n") {
+		grid = append(grid, strings.Split(row, ""))
+	}
+
+	return grid
+}
@@ -0,0 +1,97 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The native file format is plain text: a header naming the format and its
+// version, the image dimensions, and then one line of pixels per row.
+//
+//	BITMAP 1
+//	5 3
+//	OOOOO
+//	OAAAO
+//	OOOOO
+const (
+	fileMagic   = "BITMAP"
+	fileVersion = 1
+
+	maxLineLength = 1 << 20
+)
+
+// Save writes the visible layers combined into one image.
+func (e Editor) Save(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%s %d\n%d %d\n", fileMagic, fileVersion, e.cols, e.rows); err != nil {
+		return err
+	}
+
+	return e.Render(w)
+}
+
+// Load replaces the image, and any layers, with one read from a file.
+func (e *Editor) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	// A row is one line, which can be longer than a scanner allows by default.
+	scanner.Buffer(nil, maxLineLength)
+
+	var (
+		magic      string
+		version    int
+		cols, rows int
+	)
+
+	scanner.Scan()
+	if _, err := fmt.Sscanf(scanner.Text(), "%s %d", &magic, &version); err != nil || magic != fileMagic {
+		return errors.New("not a bitmap file")
+	}
+	if version != fileVersion {
+		return fmt.Errorf("unsupported bitmap file version %d", version)
+	}
+
+	scanner.Scan()
+	if _, err := fmt.Sscanf(scanner.Text(), "%d %d", &cols, &rows); err != nil || cols < 1 || rows < 1 {
+		return errors.New("invalid bitmap file dimensions")
+	}
+
+	palette, background := e.palette(), e.background()
+	pixels := newCanvas(cols, rows, background)
+	y := 0
+	for scanner.Scan() {
+		if y == rows {
+			return errors.New("bitmap file has more rows than its dimensions")
+		}
+		y++
+
+		row := scanner.Text()
+		if len(row) != cols {
+			return fmt.Errorf("bitmap file row %d has %d pixels, expected %d", y, len(row), cols)
+		}
+		for x := 0; x < cols; x++ {
+			// The background need not be among the palette's symbols, but
+			// every saved image may hold it.
+			if row[x] == background {
+				continue
+			}
+
+			char, err := palette.Resolve(row[x : x+1])
+			if err != nil {
+				return fmt.Errorf("bitmap file row %d: %w", y, err)
+			}
+			pixels.set(x+1, y, char)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if y != rows {
+		return errors.New("bitmap file has fewer rows than its dimensions")
+	}
+
+	e.rows, e.cols = rows, cols
+	e.reset(pixels)
+
+	return nil
+}
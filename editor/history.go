@@ -0,0 +1,112 @@
+package editor
+
+// historyLimit bounds the number of edits that can be undone, so that a long
+// session does not hold on to every pixel it ever touched.
+const historyLimit = 100
+
+// tileChange is a tile an edit drew on, along with its other version: the
+// tile as it was before the edit while the edit is done, and as the edit
+// left it once undone. Undoing and redoing swap the two.
+type tileChange struct {
+	pixels *canvas
+	key    point
+	tile   *tile
+}
+
+type tileRef struct {
+	pixels *canvas
+	key    point
+}
+
+// state is the shape of the image and its layers, kept for edits that change
+// more than pixels. Canvases are never drawn on once replaced, so the state
+// keeps the layers' canvases rather than copies of them.
+type state struct {
+	layers     []layer
+	active     int
+	cols, rows int
+}
+
+// edit is one undoable step: the tiles it drew on, and for an edit that
+// reshapes the image or its layers, the state from before and after.
+type edit struct {
+	tiles    []tileChange
+	from, to *state
+}
+
+// swap exchanges each tile the edit drew on with its other version.
+func (ed edit) swap() {
+	for i := range ed.tiles {
+		c := &ed.tiles[i]
+		c.tile = c.pixels.swap(c.key, c.tile)
+	}
+}
+
+// history keeps a copy of each tile an edit drew on, taken the first time
+// the edit touches it, so that an edit costs memory in proportion to the
+// tiles it reached rather than the pixels it changed.
+type history struct {
+	pending edit
+	touched map[tileRef]bool
+	done    []edit
+	undone  []edit
+}
+
+// touch notes that the pixel at (x,y) of c is about to change, keeping a copy
+// of its tile if the edit has not touched it yet.
+func (h *history) touch(c *canvas, x, y int) {
+	ref := tileRef{c, point{(x - 1) / tileSize, (y - 1) / tileSize}}
+	if h.touched[ref] {
+		return
+	}
+	if h.touched == nil {
+		h.touched = map[tileRef]bool{}
+	}
+
+	h.touched[ref] = true
+	h.pending.tiles = append(h.pending.tiles, tileChange{pixels: c, key: ref.key, tile: c.copyTile(ref.key)})
+}
+
+func (h *history) reshape(from, to state) {
+	h.pending.from, h.pending.to = &from, &to
+}
+
+// commit closes the edit being recorded. Edits that changed nothing are
+// dropped, so they cannot be undone or clear the redo stack.
+func (h *history) commit() {
+	h.touched = nil
+	if len(h.pending.tiles) == 0 && h.pending.from == nil {
+		return
+	}
+
+	h.done = append(h.done, h.pending)
+	if len(h.done) > historyLimit {
+		h.done = append([]edit(nil), h.done[1:]...)
+	}
+	h.pending = edit{}
+	h.undone = nil
+}
+
+func (h *history) undo() (edit, bool) {
+	if len(h.done) == 0 {
+		return edit{}, false
+	}
+
+	ed := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, ed)
+
+	return ed, true
+}
+
+func (h *history) redo() (edit, bool) {
+	if len(h.undone) == 0 {
+		return edit{}, false
+	}
+
+	ed := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, ed)
+
+	return ed, true
+}
@@ -0,0 +1,150 @@
+package editor
+
+import "fmt"
+
+// Transparent is the colour of pixels that let the layers beneath show
+// through. A pixel that is transparent on every visible layer shows the
+// background colour.
+const Transparent = "."
+
+// baseLayer names the layer every image starts with.
+const baseLayer = "base"
+
+// layer is one sheet of pixels in the stack. Its blank colour is what Clear
+// and Move leave behind: the background colour for the base layer,
+// transparent for the rest.
+type layer struct {
+	name   string
+	pixels *canvas
+	hidden bool
+}
+
+// AddLayer puts a new transparent layer on top of the stack and makes it the
+// one drawn on.
+func (e *Editor) AddLayer(name string) error {
+	if _, ok := e.find(name); ok {
+		return fmt.Errorf("layer '%s' already exists", name)
+	}
+
+	e.restructure(func() {
+		e.layers = append(e.layers, &layer{name: name, pixels: newCanvas(e.cols, e.rows, Transparent[0])})
+		e.active = len(e.layers) - 1
+	})
+
+	return nil
+}
+
+// SelectLayer makes the named layer the one drawn on.
+func (e *Editor) SelectLayer(name string) error {
+	i, ok := e.find(name)
+	if !ok {
+		return fmt.Errorf("no layer named '%s'", name)
+	}
+
+	e.active = i
+
+	return nil
+}
+
+// HideLayer leaves the named layer out of the image, or puts it back. Hidden
+// layers can still be drawn on.
+func (e *Editor) HideLayer(name string, hidden bool) error {
+	i, ok := e.find(name)
+	if !ok {
+		return fmt.Errorf("no layer named '%s'", name)
+	}
+	if e.layers[i].hidden == hidden {
+		return nil
+	}
+
+	e.restructure(func() {
+		e.layers[i].hidden = hidden
+	})
+
+	return nil
+}
+
+// MoveLayer moves the named layer to the given position in the stack,
+// counting up from 1 at the bottom.
+func (e *Editor) MoveLayer(name string, position int) error {
+	i, ok := e.find(name)
+	if !ok {
+		return fmt.Errorf("no layer named '%s'", name)
+	}
+	if position < 1 || position > len(e.layers) {
+		return fmt.Errorf("layer position must be between 1 and %d", len(e.layers))
+	}
+	if position-1 == i {
+		return nil
+	}
+
+	e.restructure(func() {
+		active := e.layers[e.active]
+		l := e.layers[i]
+
+		layers := append(append([]*layer(nil), e.layers[:i]...), e.layers[i+1:]...)
+		layers = append(layers[:position-1], append([]*layer{l}, layers[position-1:]...)...)
+		e.layers = layers
+
+		e.active, _ = e.find(active.name)
+	})
+
+	return nil
+}
+
+// MergeLayer draws the named layer onto the one beneath it and removes it
+// from the stack.
+func (e *Editor) MergeLayer(name string) error {
+	i, ok := e.find(name)
+	if !ok {
+		return fmt.Errorf("no layer named '%s'", name)
+	}
+	if i == 0 {
+		return fmt.Errorf("layer '%s' has no layer beneath to merge into", name)
+	}
+
+	e.restructure(func() {
+		below := e.layers[i-1].pixels
+		e.layers[i].pixels.each(func(x, y int, char byte) {
+			if char != Transparent[0] && char != below.get(x, y) {
+				e.history.touch(below, x, y)
+				below.set(x, y, char)
+			}
+		})
+
+		e.layers = append(e.layers[:i:i], e.layers[i+1:]...)
+		if e.active >= i {
+			e.active--
+		}
+	})
+
+	return nil
+}
+
+// at is the colour shown at (x,y): that of the topmost visible layer where
+// the pixel is not transparent, or the background colour if there is none.
+func (e Editor) at(x, y int) byte {
+	for i := len(e.layers) - 1; i >= 0; i-- {
+		l := e.layers[i]
+		if char := l.pixels.get(x, y); !l.hidden && char != Transparent[0] {
+			return char
+		}
+	}
+
+	return e.background()
+}
+
+func (e Editor) find(name string) (int, bool) {
+	for i, l := range e.layers {
+		if l.name == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// layer is the layer being drawn on.
+func (e Editor) layer() *layer {
+	return e.layers[e.active]
+}
@@ -0,0 +1,74 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownColour is returned when a colour is not in the editor's palette.
+var ErrUnknownColour = errors.New("unknown colour")
+
+// Palette is the set of colours an image may be drawn in. Each colour is a
+// single symbol, which is how it is stored and shown, and may also be given
+// by a name.
+type Palette struct {
+	// Symbols holds every allowed symbol.
+	Symbols string
+	// Names maps lower case names to the symbols they stand for.
+	Names map[string]string
+	// Background is the symbol of new images and of cleared pixels on the
+	// base layer. If empty, DefaultPalette's is used.
+	Background string
+}
+
+// DefaultPalette is used by editors without a palette of their own. Its names
+// are for the letters DefaultRGBPalette gives colours to.
+var DefaultPalette = Palette{
+	Symbols: "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	Names: map[string]string{
+		"white":   "O",
+		"black":   "K",
+		"red":     "R",
+		"green":   "G",
+		"blue":    "B",
+		"yellow":  "Y",
+		"cyan":    "C",
+		"magenta": "M",
+		"brown":   "N",
+		"pink":    "P",
+		"grey":    "A",
+		"gray":    "A",
+	},
+	Background: "O",
+}
+
+// Resolve is the symbol for a colour given by its symbol or, regardless of
+// case, its name. Transparent is allowed whatever the palette.
+func (p Palette) Resolve(colour string) (byte, error) {
+	if colour == Transparent || len(colour) == 1 && strings.Contains(p.Symbols, colour) {
+		return colour[0], nil
+	}
+	if symbol, ok := p.Names[strings.ToLower(colour)]; ok && symbol != "" {
+		return symbol[0], nil
+	}
+
+	return 0, fmt.Errorf("%w '%s'", ErrUnknownColour, colour)
+}
+
+func (e Editor) palette() Palette {
+	if e.Palette != nil {
+		return *e.Palette
+	}
+
+	return DefaultPalette
+}
+
+// background is the symbol of new images and cleared pixels.
+func (e Editor) background() byte {
+	if background := e.palette().Background; background != "" {
+		return background[0]
+	}
+
+	return DefaultPalette.Background[0]
+}
@@ -0,0 +1,70 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// MaxPNGSize is the largest width or height, in pixels, of an exported PNG.
+const MaxPNGSize = 8192
+
+// RGBPalette maps colour letters to the colours they are drawn with when an
+// image is exported.
+type RGBPalette map[string]color.RGBA
+
+// DefaultRGBPalette is used for any letter missing from Editor.RGB. Letters
+// in neither palette are drawn black.
+var DefaultRGBPalette = RGBPalette{
+	"O": {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	"W": {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	"K": {R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+	"R": {R: 0xff, G: 0x00, B: 0x00, A: 0xff},
+	"G": {R: 0x00, G: 0x80, B: 0x00, A: 0xff},
+	"B": {R: 0x00, G: 0x00, B: 0xff, A: 0xff},
+	"Y": {R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+	"C": {R: 0x00, G: 0xff, B: 0xff, A: 0xff},
+	"M": {R: 0xff, G: 0x00, B: 0xff, A: 0xff},
+	"N": {R: 0x80, G: 0x50, B: 0x20, A: 0xff},
+	"P": {R: 0xff, G: 0xc0, B: 0xcb, A: 0xff},
+	"A": {R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+}
+
+// PNG encodes the visible layers combined into one image, with each pixel
+// drawn as a scale x scale square.
+func (e Editor) PNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		return errors.New("png scale must be at least 1")
+	}
+	if e.cols > MaxPNGSize/scale || e.rows > MaxPNGSize/scale {
+		return fmt.Errorf("png at scale %d would be larger than %d x %d pixels", scale, MaxPNGSize, MaxPNGSize)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, e.cols*scale, e.rows*scale))
+	for y := 0; y < e.rows; y++ {
+		for x := 0; x < e.cols; x++ {
+			c := e.rgb(string(e.at(x+1, y+1)))
+			for py := y * scale; py < (y+1)*scale; py++ {
+				for px := x * scale; px < (x+1)*scale; px++ {
+					img.SetRGBA(px, py, c)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func (e Editor) rgb(char string) color.RGBA {
+	if c, ok := e.RGB[char]; ok {
+		return c
+	}
+	if c, ok := DefaultRGBPalette[char]; ok {
+		return c
+	}
+
+	return color.RGBA{A: 0xff}
+}
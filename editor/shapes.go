@@ -0,0 +1,171 @@
+package editor
+
+// Circles and ellipses are clipped to the grid: the parts that fall outside
+// are dropped rather than reported, so a shape can be partly off-canvas. Only
+// a colour missing from the palette is an error. Negative radii are treated
+// as their magnitude.
+
+func (e *Editor) Circle(x, y, r int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	e.circle(x, y, abs(r), func(dx, dy int) {
+		for _, p := range []point{{dx, dy}, {dy, dx}} {
+			e.plot(x+p.x, y+p.y, char)
+			e.plot(x-p.x, y+p.y, char)
+			e.plot(x+p.x, y-p.y, char)
+			e.plot(x-p.x, y-p.y, char)
+		}
+	})
+
+	return nil
+}
+
+func (e *Editor) FilledCircle(x, y, r int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	e.circle(x, y, abs(r), func(dx, dy int) {
+		for _, p := range []point{{dx, dy}, {dy, dx}} {
+			e.span(x-p.x, x+p.x, y+p.y, char)
+			e.span(x-p.x, x+p.x, y-p.y, char)
+		}
+	})
+
+	return nil
+}
+
+func (e *Editor) Ellipse(x, y, rx, ry int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	e.ellipse(abs(rx), abs(ry), func(dx, dy int) {
+		e.plot(x+dx, y+dy, char)
+		e.plot(x-dx, y+dy, char)
+		e.plot(x+dx, y-dy, char)
+		e.plot(x-dx, y-dy, char)
+	})
+
+	return nil
+}
+
+func (e *Editor) FilledEllipse(x, y, rx, ry int, colour string) error {
+	char, err := e.palette().Resolve(colour)
+	if err != nil {
+		return err
+	}
+
+	defer e.history.commit()
+
+	e.ellipse(abs(rx), abs(ry), func(dx, dy int) {
+		e.span(x-dx, x+dx, y+dy, char)
+		e.span(x-dx, x+dx, y-dy, char)
+	})
+
+	return nil
+}
+
+// circle walks one octant of a circle with the midpoint algorithm, handing
+// each offset from the centre to mirror, which reflects it into the others.
+func (e *Editor) circle(x, y, r int, mirror func(dx, dy int)) {
+	dx, dy := r, 0
+	d := 1 - r
+
+	for dx >= dy {
+		mirror(dx, dy)
+
+		dy++
+		if d < 0 {
+			d += 2*dy + 1
+		} else {
+			dx--
+			d += 2*(dy-dx) + 1
+		}
+	}
+}
+
+// ellipse walks one quadrant of an ellipse with the midpoint algorithm, first
+// where the slope is shallow and then where it is steep, handing each offset
+// from the centre to mirror.
+func (e *Editor) ellipse(rx, ry int, mirror func(dx, dy int)) {
+	// A flat ellipse never enters the steep region, so draw it as a line.
+	if ry == 0 {
+		for dx := 0; dx <= rx; dx++ {
+			mirror(dx, 0)
+		}
+		return
+	}
+
+	rx2, ry2 := float64(rx*rx), float64(ry*ry)
+	dx, dy := 0, ry
+	stepX, stepY := 0.0, 2*rx2*float64(dy)
+
+	d := ry2 - rx2*float64(ry) + rx2/4
+	for stepX < stepY {
+		mirror(dx, dy)
+
+		dx++
+		stepX += 2 * ry2
+		if d < 0 {
+			d += stepX + ry2
+		} else {
+			dy--
+			stepY -= 2 * rx2
+			d += stepX - stepY + ry2
+		}
+	}
+
+	hx, hy := float64(dx)+0.5, float64(dy-1)
+	d = ry2*hx*hx + rx2*hy*hy - rx2*ry2
+	for dy >= 0 {
+		mirror(dx, dy)
+
+		dy--
+		stepY -= 2 * rx2
+		if d > 0 {
+			d += rx2 - stepY
+		} else {
+			dx++
+			stepX += 2 * ry2
+			d += stepX - stepY + rx2
+		}
+	}
+}
+
+// plot colours a pixel if it is on the grid and silently drops it otherwise.
+func (e *Editor) plot(x, y int, char byte) {
+	if e.contains(x, y) {
+		e.paint(x, y, char)
+	}
+}
+
+// span colours a horizontal run, clipped to the grid.
+func (e *Editor) span(x1, x2, y int, char byte) {
+	if y < 1 || y > e.rows {
+		return
+	}
+
+	x1, x2 = ordered(x1, x2)
+	if x1 < 1 {
+		x1 = 1
+	}
+	if x2 > e.cols {
+		x2 = e.cols
+	}
+
+	for x := x1; x <= x2; x++ {
+		e.paint(x, y, char)
+	}
+}
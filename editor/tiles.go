@@ -0,0 +1,212 @@
+package editor
+
+// tileSize is the width and height, in pixels, of the square tiles a layer is
+// stored in.
+const tileSize = 64
+
+type tile [tileSize * tileSize]byte
+
+// canvas stores a layer's pixels in tiles, allocating each one only once
+// something other than the blank colour is drawn on it. A tile that was never
+// allocated reads as blank, so a mostly empty canvas costs little however
+// large it is. Tiles are keyed by their column and row, counting from 0.
+type canvas struct {
+	cols, rows int
+	blank      byte
+	tiles      map[point]*tile
+}
+
+func newCanvas(cols, rows int, blank byte) *canvas {
+	return &canvas{cols: cols, rows: rows, blank: blank, tiles: map[point]*tile{}}
+}
+
+// get is the colour of the pixel at (x,y), which must be on the canvas.
+func (c *canvas) get(x, y int) byte {
+	t, i := c.locate(x, y)
+	if t == nil {
+		return c.blank
+	}
+
+	return t[i]
+}
+
+// set colours the pixel at (x,y), which must be on the canvas.
+func (c *canvas) set(x, y int, char byte) {
+	t, i := c.locate(x, y)
+	if t == nil {
+		if char == c.blank {
+			return
+		}
+
+		t = new(tile)
+		for j := range t {
+			t[j] = c.blank
+		}
+		c.tiles[point{(x - 1) / tileSize, (y - 1) / tileSize}] = t
+	}
+
+	t[i] = char
+}
+
+func (c *canvas) locate(x, y int) (*tile, int) {
+	x, y = x-1, y-1
+
+	return c.tiles[point{x / tileSize, y / tileSize}], y%tileSize*tileSize + x%tileSize
+}
+
+func (c *canvas) contains(x, y int) bool {
+	return x >= 1 && x <= c.cols && y >= 1 && y <= c.rows
+}
+
+// drawn reports whether anything has been drawn on the canvas.
+func (c *canvas) drawn() bool {
+	return len(c.tiles) > 0
+}
+
+// each calls f for every pixel of the allocated tiles, which includes every
+// pixel that is not blank.
+func (c *canvas) each(f func(x, y int, char byte)) {
+	for key, t := range c.tiles {
+		x1, y1, x2, y2 := c.bounds(key)
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				f(x, y, t[(y-1)%tileSize*tileSize+(x-1)%tileSize])
+			}
+		}
+	}
+}
+
+// bounds is the part of the canvas covered by the tile with the given key.
+func (c *canvas) bounds(key point) (x1, y1, x2, y2 int) {
+	x1, y1 = key.x*tileSize+1, key.y*tileSize+1
+	x2, y2 = x1+tileSize-1, y1+tileSize-1
+	if x2 > c.cols {
+		x2 = c.cols
+	}
+	if y2 > c.rows {
+		y2 = c.rows
+	}
+
+	return x1, y1, x2, y2
+}
+
+// drawnWithin reports whether any allocated tile overlaps the rectangle with
+// opposite corners (x1,y1) and (x2,y2), which may reach off the canvas.
+func (c *canvas) drawnWithin(x1, y1, x2, y2 int) bool {
+	if x1 < 1 {
+		x1 = 1
+	}
+	if y1 < 1 {
+		y1 = 1
+	}
+	if x2 > c.cols {
+		x2 = c.cols
+	}
+	if y2 > c.rows {
+		y2 = c.rows
+	}
+	if x1 > x2 || y1 > y2 {
+		return false
+	}
+
+	tx1, ty1, tx2, ty2 := (x1-1)/tileSize, (y1-1)/tileSize, (x2-1)/tileSize, (y2-1)/tileSize
+
+	// Look up each tile in the rectangle, unless there are fewer allocated
+	// tiles to check against it.
+	if (tx2-tx1+1)*(ty2-ty1+1) > len(c.tiles) {
+		for key := range c.tiles {
+			if key.x >= tx1 && key.x <= tx2 && key.y >= ty1 && key.y <= ty2 {
+				return true
+			}
+		}
+		return false
+	}
+
+	for ty := ty1; ty <= ty2; ty++ {
+		for tx := tx1; tx <= tx2; tx++ {
+			if c.tiles[point{tx, ty}] != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// transform makes a cols x rows canvas, taking each pixel (x,y) from the pixel
+// of this one that from gives, or blank where that is off this canvas. from
+// must map a rectangle onto a rectangle, as shifting, flipping, turning and
+// scaling do, so that tiles with nothing drawn beneath them can be skipped.
+func (c *canvas) transform(cols, rows int, from func(x, y int) (int, int)) *canvas {
+	out := newCanvas(cols, rows, c.blank)
+
+	for ty := 0; ty*tileSize < rows; ty++ {
+		for tx := 0; tx*tileSize < cols; tx++ {
+			x1, y1, x2, y2 := out.bounds(point{tx, ty})
+			if !c.drawnWithin(corners(from, x1, y1, x2, y2)) {
+				continue
+			}
+
+			for y := y1; y <= y2; y++ {
+				for x := x1; x <= x2; x++ {
+					if fx, fy := from(x, y); c.contains(fx, fy) {
+						out.set(x, y, c.get(fx, fy))
+					}
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// copyTile is a copy of the tile with the given key, or nil if it was never
+// allocated.
+func (c *canvas) copyTile(key point) *tile {
+	t := c.tiles[key]
+	if t == nil {
+		return nil
+	}
+
+	copied := *t
+
+	return &copied
+}
+
+// swap puts t in place of the tile with the given key, a nil t leaving it
+// unallocated, and returns the tile it replaced.
+func (c *canvas) swap(key point, t *tile) *tile {
+	old := c.tiles[key]
+	if t == nil {
+		delete(c.tiles, key)
+	} else {
+		c.tiles[key] = t
+	}
+
+	return old
+}
+
+// corners is the smallest rectangle holding where from maps the corners of
+// the rectangle with opposite corners (x1,y1) and (x2,y2).
+func corners(from func(x, y int) (int, int), x1, y1, x2, y2 int) (int, int, int, int) {
+	fx1, fy1 := from(x1, y1)
+	fx2, fy2 := fx1, fy1
+
+	for _, p := range []point{{x2, y1}, {x1, y2}, {x2, y2}} {
+		fx, fy := from(p.x, p.y)
+		if fx < fx1 {
+			fx1 = fx
+		}
+		if fx > fx2 {
+			fx2 = fx
+		}
+		if fy < fy1 {
+			fy1 = fy
+		}
+		if fy > fy2 {
+			fy2 = fy
+		}
+	}
+
+	return fx1, fy1, fx2, fy2
+}
@@ -0,0 +1,169 @@
+package editor
+
+import "errors"
+
+// Rotate turns the image clockwise by the given number of degrees, which must
+// be a multiple of 90. Turning by 90 or 270 degrees swaps the image's width
+// and height.
+func (e *Editor) Rotate(degrees int) error {
+	if degrees%90 != 0 {
+		return errors.New("rotation must be a multiple of 90 degrees")
+	}
+
+	cols, rows := e.cols, e.rows
+
+	switch (degrees/90%4 + 4) % 4 {
+	case 1:
+		e.transform(rows, cols, func(x, y int) (int, int) { return y, rows - x + 1 })
+	case 2:
+		e.transform(cols, rows, func(x, y int) (int, int) { return cols - x + 1, rows - y + 1 })
+	case 3:
+		e.transform(rows, cols, func(x, y int) (int, int) { return cols - y + 1, x })
+	}
+
+	return nil
+}
+
+// FlipHorizontal mirrors the image left to right.
+func (e *Editor) FlipHorizontal() {
+	cols := e.cols
+	e.transform(e.cols, e.rows, func(x, y int) (int, int) { return cols - x + 1, y })
+}
+
+// FlipVertical mirrors the image top to bottom.
+func (e *Editor) FlipVertical() {
+	rows := e.rows
+	e.transform(e.cols, e.rows, func(x, y int) (int, int) { return x, rows - y + 1 })
+}
+
+// Transpose mirrors the image along the diagonal from its top left corner,
+// swapping its width and height.
+func (e *Editor) Transpose() {
+	e.transform(e.rows, e.cols, func(x, y int) (int, int) { return y, x })
+}
+
+// Resize changes the size of the image to cols x rows without stretching it.
+// Existing pixels are shifted dx columns right and dy rows down; any that
+// fall off the new image are lost, and new space is each layer's blank
+// colour: the background on the base layer, transparent on the rest.
+func (e *Editor) Resize(cols, rows, dx, dy int) {
+	e.transform(cols, rows, func(x, y int) (int, int) { return x - dx, y - dy })
+}
+
+// Scale stretches or shrinks the image to cols x rows, taking each pixel from
+// the nearest pixel of the old image.
+func (e *Editor) Scale(cols, rows int) {
+	oldCols, oldRows := e.cols, e.rows
+	e.transform(cols, rows, func(x, y int) (int, int) {
+		return (x-1)*oldCols/cols + 1, (y-1)*oldRows/rows + 1
+	})
+}
+
+// transform replaces every layer with a cols x rows one, taking each pixel
+// (x,y) from the pixel of the old layer that from gives, or the layer's blank
+// colour where that is off the old image.
+func (e *Editor) transform(cols, rows int, from func(x, y int) (int, int)) {
+	e.restructure(func() {
+		for _, l := range e.layers {
+			l.pixels = l.pixels.transform(cols, rows, from)
+		}
+		e.cols, e.rows = cols, rows
+	})
+}
+
+// restructure makes a change to the shape of the image or its layers as one
+// undoable edit.
+func (e *Editor) restructure(change func()) {
+	// An editor that has never had an image gets its base layer first, so
+	// that a resize or a new layer has something to stack on.
+	if len(e.layers) == 0 {
+		e.reset(newCanvas(e.cols, e.rows, e.background()))
+	}
+
+	before := e.snapshot()
+	change()
+
+	e.history.reshape(before, e.snapshot())
+	e.history.commit()
+}
+
+// snapshot records the layers without copying their pixels. A restructure
+// gives a layer a new canvas rather than drawing on its old one, and any
+// drawing after is recorded tile by tile, so the canvases a state holds
+// match the state whenever it is restored.
+func (e Editor) snapshot() state {
+	s := state{
+		layers: make([]layer, len(e.layers)),
+		active: e.active,
+		cols:   e.cols,
+		rows:   e.rows,
+	}
+	for i, l := range e.layers {
+		s.layers[i] = *l
+	}
+
+	return s
+}
+
+// restore puts back the layers as they were when the state was taken.
+func (e *Editor) restore(s state) {
+	e.layers = make([]*layer, len(s.layers))
+	for i := range s.layers {
+		l := s.layers[i]
+		e.layers[i] = &l
+	}
+	e.active, e.cols, e.rows = s.active, s.cols, s.rows
+}
+
+// Crop cuts the image down to the rectangle with opposite corners (x1,y1)
+// and (x2,y2), which must lie on the grid.
+func (e *Editor) Crop(x1, y1, x2, y2 int) error {
+	if !e.contains(x1, y1) || !e.contains(x2, y2) {
+		return ErrOutOfBounds
+	}
+
+	x1, x2 = ordered(x1, x2)
+	y1, y2 = ordered(y1, y2)
+
+	e.transform(x2-x1+1, y2-y1+1, func(x, y int) (int, int) { return x + x1 - 1, y + y1 - 1 })
+
+	return nil
+}
+
+// CropToContent cuts the image down to the smallest rectangle holding every
+// pixel that is not the background colour, looking through all the visible
+// layers. Only the tiles drawn on are searched.
+func (e *Editor) CropToContent() error {
+	x1, y1, x2, y2 := e.cols+1, e.rows+1, 0, 0
+	background := e.background()
+
+	for _, l := range e.layers {
+		if l.hidden {
+			continue
+		}
+
+		l.pixels.each(func(x, y int, _ byte) {
+			if e.at(x, y) == background {
+				return
+			}
+			if x < x1 {
+				x1 = x
+			}
+			if x > x2 {
+				x2 = x
+			}
+			if y < y1 {
+				y1 = y
+			}
+			if y > y2 {
+				y2 = y
+			}
+		})
+	}
+
+	if x2 == 0 {
+		return errors.New("nothing to crop to, the image is blank")
+	}
+
+	return e.Crop(x1, y1, x2, y2)
+}
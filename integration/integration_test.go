@@ -1,8 +1,14 @@
 package integration_test
 
 import (
+	"image/png"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -43,6 +49,18 @@ var _ = Describe("Integration", func() {
 				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(session.Out).Should(gbytes.Say("invalid image value: unrecognised command 'X', use 'I' for Image initialisation"))
+				Eventually(session).Should(gexec.Exit(2))
+			})
+		})
+
+		Context("if the image size is out of range", func() {
+			It("the program will complain and exit with a non-zero status", func() {
+				_, err := io.WriteString(inBuf, "I 0 5")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(3))
 			})
 		})
 	})
@@ -113,6 +131,105 @@ var _ = Describe("Integration", func() {
 		})
 	})
 
+	Describe("'D': drawing a straight line", func() {
+		It("sets the pixels along the line", func() {
+			_, err := io.WriteString(inBuf, "I 5 5\nD 1 5 5 1 X\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OOOOX\nOOOXO\nOOXOO\nOXOOO\nXOOOO\n"))
+		})
+
+		Context("if the line extends past the image", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "I 5 5\nD 1 1 7 7 X")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say("given coordinate is beyond image grid"))
+			})
+		})
+	})
+
+	Describe("'R': drawing a rectangle outline", func() {
+		It("sets the pixels on the edges of the rectangle", func() {
+			_, err := io.WriteString(inBuf, "I 5 5\nR 2 2 4 5 Q\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OOOOO\nOQQQO\nOQOQO\nOQOQO\nOQQQO\n"))
+		})
+	})
+
+	Describe("'B': drawing a filled rectangle", func() {
+		It("sets every pixel inside the rectangle", func() {
+			_, err := io.WriteString(inBuf, "I 5 5\nB 4 3 2 1 Q\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OQQQO\nOQQQO\nOQQQO\nOOOOO\nOOOOO\n"))
+		})
+
+		Context("if the action cannot be processed", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "I 5 5\nB 1 1 6 2 Q")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say("given coordinate is beyond image grid"))
+			})
+		})
+	})
+
+	Describe("'O' and 'FO': drawing circles", func() {
+		It("sets the pixels on and inside the circles", func() {
+			_, err := io.WriteString(inBuf, "I 5 5\nO 3 3 2 C\nFO 3 3 1 D\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OCCCO\nCODOC\nCDDDC\nCODOC\nOCCCO\n"))
+		})
+	})
+
+	Describe("'E' and 'FE': drawing ellipses", func() {
+		It("sets the pixels on and inside the ellipses, clipped to the image", func() {
+			_, err := io.WriteString(inBuf, "I 5 3\nE 3 2 2 1 E\nFE 5 2 1 1 F\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OEEEF\nEOOFF\nOEEEF\n"))
+		})
+	})
+
+	Describe("'F': filling a region", func() {
+		It("recolours the pixels connected to the given coordinate", func() {
+			_, err := io.WriteString(inBuf, "I 5 5\nV 2 1 5 W\nF 4 3 Z\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OWZZZ\nOWZZZ\nOWZZZ\nOWZZZ\nOWZZZ\n"))
+		})
+
+		Context("if the action cannot be processed", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "I 5 5\nF 6 1 Z")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say("given coordinate is beyond image grid"))
+			})
+		})
+	})
+
 	Describe("'S': showing the image", func() {
 		Context("whenever the user inputs the 'S' command", func() {
 			It("the image is printed in its current state", func() {
@@ -124,6 +241,31 @@ var _ = Describe("Integration", func() {
 				Eventually(session.Out).Should(gbytes.Say("OOOOO\nOOOOO\nOOOOO\nOOOOO\nOOOOO\n"))
 			})
 		})
+
+		Context("with COLOR", func() {
+			It("draws each pixel as a coloured block", func() {
+				_, err := io.WriteString(inBuf, "I 2 1\nL 2 1 R\nS COLOR")
+				Expect(err).NotTo(HaveOccurred())
+
+				cliCmd.Env = append(os.Environ(), "COLORTERM=truecolor")
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say(regexp.QuoteMeta("\x1b[48;2;255;255;255m  \x1b[48;2;255;0;0m  \x1b[0m\n")))
+			})
+		})
+
+		Context("with the -color flag, when output is not a terminal", func() {
+			It("keeps showing letters", func() {
+				_, err := io.WriteString(inBuf, "I 2 1\nL 2 1 R\nS")
+				Expect(err).NotTo(HaveOccurred())
+
+				cliCmd.Args = append(cliCmd.Args, "-color")
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out.Contents()).To(Equal([]byte("OR\n\n")))
+			})
+		})
 	})
 
 	Describe("'C': clearing the image", func() {
@@ -137,9 +279,437 @@ var _ = Describe("Integration", func() {
 		})
 	})
 
+	Describe("'Y', 'P' and 'M': copying, pasting and moving regions", func() {
+		It("copies a region and pastes it elsewhere, clipped at the edge", func() {
+			_, err := io.WriteString(inBuf, "I 4 3\nL 1 1 A\nL 2 2 B\nY 1 1 2 2\nP 3 2\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("AOOO\nOBAO\nOOOB\n"))
+		})
+
+		It("moves a region, leaving white behind", func() {
+			_, err := io.WriteString(inBuf, "I 4 3\nL 1 1 A\nL 2 2 B\nM 1 1 2 2 1 0\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OAOO\nOOBO\nOOOO\n"))
+		})
+	})
+
+	Describe("'RO', 'FH', 'FV' and 'T': transforming the image", func() {
+		It("rotates the image, keeping edits within its new shape", func() {
+			_, err := io.WriteString(inBuf, "I 3 2\nL 1 1 A\nRO 90\nL 1 3 B\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OA\nOO\nBO\n"))
+		})
+
+		It("flips the image", func() {
+			_, err := io.WriteString(inBuf, "I 3 2\nL 1 1 A\nFH\nS\nFV\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OOA\nOOO\n"))
+			Eventually(session.Out).Should(gbytes.Say("OOO\nOOA\n"))
+		})
+	})
+
+	Describe("'Z' and 'K': resizing and scaling the image", func() {
+		It("resizes the canvas around the image", func() {
+			_, err := io.WriteString(inBuf, "I 2 2\nL 1 1 A\nZ 4 4 C\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OOOO\nOAOO\nOOOO\nOOOO\n"))
+		})
+
+		It("scales the image", func() {
+			_, err := io.WriteString(inBuf, "I 2 2\nL 1 1 A\nK 2\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("AAOO\nAAOO\nOOOO\nOOOO\n"))
+		})
+	})
+
+	Describe("'X': cropping the image", func() {
+		It("crops to a region or to the content", func() {
+			_, err := io.WriteString(inBuf, "I 6 6\nL 2 3 A\nL 4 4 B\nX AUTO\nS\nX 2 1 3 2\nS\nL 3 1 C\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("AOO\nOOB\n"))
+			Eventually(session.Out).Should(gbytes.Say("OO\nOB\n"))
+			Eventually(session.Out).Should(gbytes.Say("given coordinate is beyond image grid"))
+		})
+	})
+
+	Describe("'LN', 'LS', 'LH', 'LV', 'LO' and 'LM': layers", func() {
+		It("keeps annotations apart from the background", func() {
+			_, err := io.WriteString(inBuf, "I 3 2\nH 1 3 1 G\nLN notes\nL 2 2 A\nS\nC\nS\nL 3 2 B\nLH notes\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("GGG\nOAO\n"))
+			Eventually(session.Out).Should(gbytes.Say("GGG\nOOO\n"))
+			Eventually(session.Out).Should(gbytes.Say("GGG\nOOO\n"))
+		})
+	})
+
+	Describe("'I name', 'USE', 'DOCS', 'CLOSE' and 'COPY': documents", func() {
+		It("builds a sprite sheet from several documents", func() {
+			_, err := io.WriteString(inBuf, "I sheet 6 3\nI icon 3 3\nR 1 1 3 3 A\nUSE sheet\nCOPY icon 1 1\nCOPY icon 4 1\nDOCS\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("  icon 3x3\n  main 0x0\n\\* sheet 6x3\n"))
+			Expect(session.Out).To(gbytes.Say("AAAAAA\nAOAAOA\nAAAAAA\n"))
+		})
+
+		It("keeps documents from one script to the next", func() {
+			dir, err := ioutil.TempDir("", "integration")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			first := filepath.Join(dir, "first.bmp")
+			Expect(ioutil.WriteFile(first, []byte("I 2 2\nI icon 2 2\nL 1 1 A\n"), 0644)).To(Succeed())
+			second := filepath.Join(dir, "second.bmp")
+			Expect(ioutil.WriteFile(second, []byte("USE main\nCOPY icon 2 2\nS\n"), 0644)).To(Succeed())
+			cliCmd.Args = append(cliCmd.Args, first, second)
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("OO\nOA\n"))
+		})
+	})
+
+	Describe("'U' and 'R': undoing and redoing changes", func() {
+		It("steps backwards and forwards through the edits", func() {
+			_, err := io.WriteString(inBuf, "I 2 2\nL 1 1 A\nH 1 2 2 B\nU\nS\nR\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("AO\nOO\n\nAO\nBB\n"))
+		})
+
+		Context("if there is nothing to undo", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nU")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say("nothing to undo"))
+			})
+		})
+	})
+
+	Describe("'W' and 'O': writing and opening image files", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "integration")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("restores a previously written image", func() {
+			path := filepath.Join(dir, "image.bmp")
+			_, err := io.WriteString(inBuf, "I 3 2\nL 2 1 A\nW "+path+"\nC\nO "+path+"\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("OAO\nOOO\n"))
+		})
+
+		Context("if the image is larger than the size limits", func() {
+			It("prints an error and keeps the image", func() {
+				path := filepath.Join(dir, "wide.bmp")
+				Expect(ioutil.WriteFile(path, []byte("BITMAP 1\n70000 1\n"+strings.Repeat("O", 70000)+"\n"), 0644)).To(Succeed())
+				_, err := io.WriteString(inBuf, "I 3 2\nO "+path+"\nDOCS")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("image axis out of range"))
+				Expect(session.Out).To(gbytes.Say(`\* main 3x2`))
+			})
+		})
+
+		Context("if the file is not an image", func() {
+			It("prints an error", func() {
+				path := filepath.Join(dir, "notes.txt")
+				Expect(ioutil.WriteFile(path, []byte("hello\n"), 0644)).To(Succeed())
+				_, err := io.WriteString(inBuf, "I 3 2\nO "+path)
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session.Out).Should(gbytes.Say("not a bitmap file"))
+			})
+		})
+	})
+
+	Describe("'P' and -png: exporting PNG files", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "integration")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		decode := func(path string) (int, int) {
+			f, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			img, err := png.Decode(f)
+			Expect(err).NotTo(HaveOccurred())
+
+			return img.Bounds().Dx(), img.Bounds().Dy()
+		}
+
+		It("writes the image at the given scale", func() {
+			path := filepath.Join(dir, "image.png")
+			_, err := io.WriteString(inBuf, "I 3 2\nL 2 1 R\nP "+path+" 2\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			width, height := decode(path)
+			Expect(width).To(Equal(6))
+			Expect(height).To(Equal(4))
+		})
+
+		It("writes the final image when given the -png flag", func() {
+			path := filepath.Join(dir, "final.png")
+			cliCmd.Args = append(cliCmd.Args, "-png", path, "-scale", "3")
+			_, err := io.WriteString(inBuf, "I 3 2\nL 2 1 R\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			width, height := decode(path)
+			Expect(width).To(Equal(9))
+			Expect(height).To(Equal(6))
+		})
+	})
+
+	Describe("running script files", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "integration")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		script := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			return path
+		}
+
+		It("runs each script in order against the same image", func() {
+			first := script("first.bmp", "I 3 2\nL 1 1 A\n")
+			second := script("second.bmp", "L 3 2 B\nS\n")
+			cliCmd.Args = append(cliCmd.Args, first, second)
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("AOO\nOOB\n"))
+		})
+
+		It("reads standard input in place of '-'", func() {
+			first := script("first.bmp", "I 3 2\nL 1 1 A\n")
+			cliCmd.Args = append(cliCmd.Args, first, "-")
+			_, err := io.WriteString(inBuf, "L 2 2 C\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("AOO\nOCO\n"))
+		})
+
+		It("reports errors with the script name and line number", func() {
+			path := script("shapes.bmp", "I 3 3\nL 1 1 A\nL 1 4 A\n")
+			cliCmd.Args = append(cliCmd.Args, path)
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say(regexp.QuoteMeta(path) + ":3: given coordinate is beyond image grid"))
+		})
+
+		It("accepts comments, blank lines and any spacing", func() {
+			path := script("spaced.bmp", "# a small picture\nI  3\t2\n\n\tL 1 1 A   # top left\n\n# done\nS\n")
+			cliCmd.Args = append(cliCmd.Args, path)
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("AOO\nOOO\n"))
+			Expect(session.Out.Contents()).NotTo(ContainSubstring("invalid"))
+		})
+
+		Context("if a script cannot be opened", func() {
+			It("complains and exits", func() {
+				cliCmd.Args = append(cliCmd.Args, filepath.Join(dir, "missing.bmp"))
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(4))
+				Expect(session.Out).To(gbytes.Say("could not open script"))
+			})
+		})
+	})
+
+	Describe("SET, REPEAT and DEF: scripting", func() {
+		It("draws with variables, loops and macros", func() {
+			_, err := io.WriteString(inBuf, "I 4 4\nDEF diag c\nREPEAT 4 i\nL $i $i $c\nEND\nEND\nSET c x\ndiag $c\nL 4 4-3 Y\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("XOOY\nOXOO\nOOXO\nOOOX\n"))
+		})
+
+		It("sizes the image from variables set before it", func() {
+			_, err := io.WriteString(inBuf, "SET n 3\nDEF dot x\nL $x $x A\nEND\nI $n $n-1\ndot 2\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("OOO\nOAO\n"))
+		})
+	})
+
+	Describe("-strict: stopping at the first error", func() {
+		BeforeEach(func() {
+			cliCmd.Args = append(cliCmd.Args, "-strict")
+		})
+
+		It("exits successfully if every command succeeds", func() {
+			_, err := io.WriteString(inBuf, "I 2 2\nL 1 1 A\nS\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+		})
+
+		Context("if a command cannot be parsed", func() {
+			It("stops and exits with status 2", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nL 1\nS\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Out).To(gbytes.Say("L expects 2 coordinates and a colour, got 1"))
+				Expect(session.Out).NotTo(gbytes.Say("OO\nOO"))
+			})
+		})
+
+		Context("if a colour is not in the palette", func() {
+			It("stops and exits with status 2", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nL 1 1 red\nS\nL 2 1 RED!\nS\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Out).To(gbytes.Say("RO\nOO\n"))
+				Expect(session.Out).To(gbytes.Say("unknown colour 'RED!'"))
+			})
+		})
+
+		Context("if a command reaches beyond the image", func() {
+			It("stops and exits with status 3", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nL 1 3 A\nS\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(3))
+				Expect(session.Out).To(gbytes.Say("given coordinate is beyond image grid"))
+			})
+		})
+
+		Context("if a command is given a number far beyond any image", func() {
+			It("stops and exits with status 3", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nO 1 1 100000000 A\nS\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(3))
+				Expect(session.Out).To(gbytes.Say("number out of range"))
+			})
+		})
+
+		Context("if a file cannot be read", func() {
+			It("stops and exits with status 4", func() {
+				_, err := io.WriteString(inBuf, "I 2 2\nO /does/not/exist.bmp\nS\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(4))
+			})
+		})
+	})
+
+	Context("an action with missing arguments", func() {
+		It("complains and carries on", func() {
+			_, err := io.WriteString(inBuf, "I 2 2\nL 1\nL 1 1 A\nS")
+			Expect(err).NotTo(HaveOccurred())
+
+			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session.Out).Should(gbytes.Say("L expects 2 coordinates and a colour, got 1"))
+			Eventually(session.Out).Should(gbytes.Say("AO\nOO\n"))
+		})
+	})
+
 	Context("any other attempted action", func() {
 		It("complains", func() {
-			_, err := io.WriteString(inBuf, "I 2 2\nP 1 1 A\n")
+			_, err := io.WriteString(inBuf, "I 2 2\nQ 1 1 A\n")
 			Expect(err).NotTo(HaveOccurred())
 
 			session, err := gexec.Start(cliCmd, GinkgoWriter, GinkgoWriter)
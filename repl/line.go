@@ -0,0 +1,220 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	keyCtrlA     = 0x01
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyBackspace = 0x08
+	keyTab       = 0x09
+	keyEnter     = 0x0d
+	keyNewline   = 0x0a
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// Editor reads lines from a terminal in raw mode, echoing and editing them
+// itself. It keeps a history of entered lines, navigable with the up and
+// down arrows, and completes the first word with tab.
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	complete func(prefix string) []string
+	history  []string
+}
+
+func NewEditor(in io.Reader, out io.Writer, complete func(prefix string) []string) *Editor {
+	return &Editor{in: bufio.NewReader(in), out: out, complete: complete}
+}
+
+// line is the state of the line being edited.
+type line struct {
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	l := &line{prompt: prompt}
+	index, draft := len(e.history), ""
+	e.redraw(l)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprint(e.out, "\r\n")
+			text := string(l.buf)
+			if strings.TrimSpace(text) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != text) {
+				e.history = append(e.history, text)
+			}
+			return text, nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			l.buf, l.pos = nil, 0
+			index = len(e.history)
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				return "", io.EOF
+			}
+			l.deleteForward()
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyBackspace, keyDelete:
+			l.deleteBackward()
+		case keyTab:
+			e.completeWord(l)
+		case keyEscape:
+			switch e.escape() {
+			case 'A':
+				if index > 0 {
+					if index == len(e.history) {
+						draft = string(l.buf)
+					}
+					index--
+					l.set(e.history[index])
+				}
+			case 'B':
+				if index < len(e.history) {
+					index++
+					if index == len(e.history) {
+						l.set(draft)
+					} else {
+						l.set(e.history[index])
+					}
+				}
+			case 'C':
+				if l.pos < len(l.buf) {
+					l.pos++
+				}
+			case 'D':
+				if l.pos > 0 {
+					l.pos--
+				}
+			case 'H':
+				l.pos = 0
+			case 'F':
+				l.pos = len(l.buf)
+			case '~':
+				l.deleteForward()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				l.insert(r)
+			}
+		}
+
+		e.redraw(l)
+	}
+}
+
+// escape reads the rest of an escape sequence, returning its final byte.
+// Delete arrives as "ESC [ 3 ~", and is returned as '~'.
+func (e *Editor) escape() rune {
+	if r, _, err := e.in.ReadRune(); err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return 0
+		}
+		if r < '0' || r > '9' {
+			return r
+		}
+	}
+}
+
+// completeWord completes the command name, the first word on the line. A
+// single match is filled in; several are listed below the line.
+func (e *Editor) completeWord(l *line) {
+	text := string(l.buf[:l.pos])
+	if e.complete == nil || strings.ContainsAny(text, " \t") {
+		return
+	}
+
+	matches := e.complete(text)
+	switch len(matches) {
+	case 0:
+	case 1:
+		l.set(matches[0] + " " + string(l.buf[l.pos:]))
+		l.pos = len([]rune(matches[0])) + 1
+	default:
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(matches, "  "))
+	}
+}
+
+func (e *Editor) redraw(l *line) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (l *line) set(text string) {
+	l.buf = []rune(text)
+	l.pos = len(l.buf)
+}
+
+func (l *line) insert(r rune) {
+	l.buf = append(l.buf, 0)
+	copy(l.buf[l.pos+1:], l.buf[l.pos:])
+	l.buf[l.pos] = r
+	l.pos++
+}
+
+func (l *line) deleteBackward() {
+	if l.pos == 0 {
+		return
+	}
+
+	l.buf = append(l.buf[:l.pos-1], l.buf[l.pos:]...)
+	l.pos--
+}
+
+func (l *line) deleteForward() {
+	if l.pos == len(l.buf) {
+		return
+	}
+
+	l.buf = append(l.buf[:l.pos], l.buf[l.pos+1:]...)
+}
+
+// Plain reads lines from input the terminal already echoes and edits, such
+// as when raw mode is unavailable.
+type Plain struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func NewPlain(in io.Reader, out io.Writer) *Plain {
+	return &Plain{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (p *Plain) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return p.scanner.Text(), nil
+}
@@ -0,0 +1,93 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mo-work/go-technical-test-for-claudia/runner"
+)
+
+// LineReader reads one line of input after showing a prompt. It returns
+// io.EOF when the user ends the session.
+type LineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+type Shell struct {
+	runner *runner.Runner
+	lines  LineReader
+	out    io.Writer
+}
+
+func New(r *runner.Runner, lines LineReader, out io.Writer) *Shell {
+	return &Shell{runner: r, lines: lines, out: out}
+}
+
+// Run reads and runs commands until the input ends. Failed commands are
+// printed and the session carries on.
+func (s *Shell) Run() error {
+	fmt.Fprintln(s.out, "Type ? for a list of commands, Ctrl-D to quit.")
+
+	for {
+		line, err := s.lines.ReadLine(s.prompt())
+		if err == io.EOF {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "":
+		case "?", "help":
+			if err := runner.Help(s.out); err != nil {
+				return err
+			}
+		default:
+			if err := s.runner.Execute(line); err != nil {
+				fmt.Fprintln(s.out, err)
+			}
+		}
+	}
+}
+
+func (s *Shell) prompt() string {
+	if s.runner.Pending() {
+		return "... "
+	}
+
+	cols, rows := s.runner.Size()
+	if cols == 0 || rows == 0 {
+		return "no image> "
+	}
+
+	return fmt.Sprintf("%dx%d> ", cols, rows)
+}
+
+// Complete suggests command names that start with the given prefix.
+func Complete(prefix string) []string {
+	prefix = strings.ToUpper(prefix)
+
+	var matches []string
+	for _, name := range runner.Names() {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+
+	return matches
+}
+
+// IsTerminal reports whether f is an interactive terminal rather than a
+// file or pipe.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,169 @@
+package repl_test
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mo-work/go-technical-test-for-claudia/repl"
+	"github.com/mo-work/go-technical-test-for-claudia/runner"
+	"github.com/mo-work/go-technical-test-for-claudia/runner/runnerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Shell", func() {
+	var (
+		outBuf          *gbytes.Buffer
+		fakeImageEditor *runnerfakes.FakeImageEditor
+		shell           *repl.Shell
+	)
+
+	start := func(input string) {
+		r := runner.New(nil, outBuf, fakeImageEditor)
+		shell = repl.New(r, repl.NewPlain(strings.NewReader(input), outBuf), outBuf)
+	}
+
+	BeforeEach(func() {
+		outBuf = gbytes.NewBuffer()
+		fakeImageEditor = new(runnerfakes.FakeImageEditor)
+	})
+
+	It("runs each line as a command", func() {
+		start("L 1 2 A\nC\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+		Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+	})
+
+	It("shows the image size in the prompt", func() {
+		fakeImageEditor.SizeReturns(5, 7)
+		start("C\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say("5x7> "))
+	})
+
+	It("says when there is no image yet", func() {
+		start("")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say("no image> "))
+	})
+
+	It("shows a continuation prompt inside a block", func() {
+		start("REPEAT 2\nC\nEND\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say(`no image> \.\.\. \.\.\. no image> `))
+		Expect(fakeImageEditor.ClearCallCount()).To(Equal(2))
+	})
+
+	It("carries on past blank lines", func() {
+		start("\n\nC\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+	})
+
+	It("prints failed commands and carries on", func() {
+		start("L 1\nC\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say("L expects 2 coordinates and a colour, got 1"))
+		Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+	})
+
+	It("lists every command on ?", func() {
+		start("?\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say(`L X Y C\s+Colours the pixel`))
+		Expect(outBuf).To(gbytes.Say(`P path \[scale\]\s+Exports the image`))
+	})
+
+	It("lists every command on help", func() {
+		start("help\n")
+
+		Expect(shell.Run()).To(Succeed())
+		Expect(outBuf).To(gbytes.Say(`L X Y C\s+Colours the pixel`))
+	})
+})
+
+var _ = Describe("Complete", func() {
+	It("suggests the command names starting with the prefix", func() {
+		Expect(repl.Complete("f")).To(Equal([]string{"F", "FE", "FH", "FO", "FV"}))
+		Expect(repl.Complete("FO")).To(Equal([]string{"FO"}))
+		Expect(repl.Complete("Q")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Editor", func() {
+	var outBuf *gbytes.Buffer
+
+	BeforeEach(func() {
+		outBuf = gbytes.NewBuffer()
+	})
+
+	complete := func(prefix string) []string {
+		var matches []string
+		for _, name := range []string{"FE", "FO", "L"} {
+			if strings.HasPrefix(name, strings.ToUpper(prefix)) {
+				matches = append(matches, name)
+			}
+		}
+		return matches
+	}
+
+	readLines := func(keys string, n int) []string {
+		e := repl.NewEditor(strings.NewReader(keys), outBuf, complete)
+
+		var lines []string
+		for i := 0; i < n; i++ {
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			lines = append(lines, line)
+		}
+
+		return lines
+	}
+
+	It("echoes what is typed after the prompt", func() {
+		Expect(readLines("L 1 1 A\r", 1)).To(Equal([]string{"L 1 1 A"}))
+		Expect(outBuf).To(gbytes.Say("> L 1 1 A"))
+	})
+
+	It("deletes with backspace", func() {
+		Expect(readLines("L 1 1 AB\x7f\r", 1)).To(Equal([]string{"L 1 1 A"}))
+	})
+
+	It("moves the cursor with the left and right arrows", func() {
+		Expect(readLines("L 1 A\x1b[D1 \x1b[C\r", 1)).To(Equal([]string{"L 1 1 A"}))
+	})
+
+	It("recalls earlier lines with the up and down arrows", func() {
+		lines := readLines("C\rS\r\x1b[A\x1b[A\r\x1b[A\x1b[A\x1b[A\x1b[B\r", 4)
+		Expect(lines).To(Equal([]string{"C", "S", "C", "S"}))
+	})
+
+	It("completes a unique command name with tab", func() {
+		Expect(readLines("fo\t1 2 3 A\r", 1)).To(Equal([]string{"FO 1 2 3 A"}))
+	})
+
+	It("lists the candidates when the command name is ambiguous", func() {
+		Expect(readLines("f\t\x7f\x7fL\r", 1)).To(Equal([]string{"L"}))
+		Expect(outBuf).To(gbytes.Say("FE  FO"))
+	})
+
+	It("discards the line on Ctrl-C", func() {
+		Expect(readLines("L 1\x03C\r", 1)).To(Equal([]string{"C"}))
+	})
+
+	It("ends the input on Ctrl-D at an empty line", func() {
+		e := repl.NewEditor(strings.NewReader("\x04"), outBuf, complete)
+
+		_, err := e.ReadLine("> ")
+		Expect(err).To(Equal(io.EOF))
+	})
+})
@@ -0,0 +1,37 @@
+package repl
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// MakeRaw puts the terminal into raw mode, so that keys arrive one at a time
+// without being echoed, and returns a function that restores it. Output
+// processing is left on so that newlines still return the carriage.
+func MakeRaw(fd uintptr) (func() error, error) {
+	var old syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return ioctl(fd, syscall.TCSETS, &old)
+	}, nil
+}
+
+func ioctl(fd uintptr, request uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
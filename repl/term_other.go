@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package repl
+
+import "errors"
+
+// MakeRaw is only supported on Linux; elsewhere the shell falls back to
+// reading whole lines.
+func MakeRaw(fd uintptr) (func() error, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,658 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type argKind int
+
+const (
+	coordArg argKind = iota
+	numberArg
+	colourArg
+	pathArg
+	anchorArg
+	keywordArg
+	optionArg
+	nameArg
+)
+
+// anchors place an image on a resized canvas, as the share of the new space
+// that goes before it: 0 for none, 1 for half, 2 for all.
+var anchors = map[string][2]int{
+	"TL": {0, 0}, "T": {1, 0}, "TR": {2, 0},
+	"L": {0, 1}, "C": {1, 1}, "R": {2, 1},
+	"BL": {0, 2}, "B": {1, 2}, "BR": {2, 2},
+}
+
+// arg describes one argument of an action: its name in the usage line, how
+// it is parsed, and the noun used for it in error messages.
+type arg struct {
+	name     string
+	kind     argKind
+	noun     string
+	optional bool
+}
+
+func coord(name string) arg {
+	return arg{name: name, kind: coordArg, noun: "coordinate"}
+}
+
+func number(name, noun string) arg {
+	return arg{name: name, kind: numberArg, noun: noun}
+}
+
+func colour() arg {
+	return arg{name: "C", kind: colourArg, noun: "colour"}
+}
+
+func path() arg {
+	return arg{name: "path", kind: pathArg, noun: "path"}
+}
+
+func anchor() arg {
+	return arg{name: "anchor", kind: anchorArg, noun: "anchor"}
+}
+
+// named is an argument naming a layer or document.
+func named(noun string) arg {
+	return arg{name: "name", kind: nameArg, noun: noun}
+}
+
+// keyword is an argument that must be the given word.
+func keyword(word string) arg {
+	return arg{name: word, kind: keywordArg, noun: word}
+}
+
+// option is an optional word that switches on part of what an action does.
+// An action's options come last and may be given in any order.
+func option(word string) arg {
+	return arg{name: word, kind: optionArg, noun: word, optional: true}
+}
+
+func optional(a arg) arg {
+	a.optional = true
+	return a
+}
+
+// action is one entry of the command table. An action name may appear more
+// than once with different arguments; the first entry that fits wins.
+type action struct {
+	name string
+	args []arg
+	help string
+	run  func(r *Runner, c Command) error
+}
+
+var actions = []action{
+	{
+		name: "I",
+		args: []arg{number("M", "width"), number("N", "height")},
+		help: "Creates a new M x N image with all pixels coloured white (O).",
+		run: func(r *Runner, c Command) error {
+			return r.createImage(c.Coords[0], c.Coords[1])
+		},
+	},
+	{
+		name: "I",
+		args: []arg{named("document name"), number("M", "width"), number("N", "height")},
+		help: "Creates a new M x N document called name, or replaces it, and switches to it.",
+		run: func(r *Runner, c Command) error {
+			return r.createDocument(c.Name, c.Coords[0], c.Coords[1])
+		},
+	},
+	{
+		name: "USE",
+		args: []arg{named("document name")},
+		help: "Switches to another document.",
+		run: func(r *Runner, c Command) error {
+			return r.useDocument(c.Name)
+		},
+	},
+	{
+		name: "DOCS",
+		help: "Lists the documents, marking the one in use with *.",
+		run: func(r *Runner, c Command) error {
+			r.listDocuments()
+			return nil
+		},
+	},
+	{
+		name: "CLOSE",
+		args: []arg{named("document name")},
+		help: "Closes a document other than the one in use.",
+		run: func(r *Runner, c Command) error {
+			return r.closeDocument(c.Name)
+		},
+	},
+	{
+		name: "COPY",
+		args: []arg{named("document name"), coord("X"), coord("Y")},
+		help: "Copies another document into this one with its top left corner at (X,Y).",
+		run: func(r *Runner, c Command) error {
+			return r.copyDocument(c.Name, c.Coords[0], c.Coords[1])
+		},
+	},
+	{
+		name: "L",
+		args: []arg{coord("X"), coord("Y"), colour()},
+		help: "Colours the pixel (X,Y) with colour C.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Set(c.Coords[0], c.Coords[1], c.Char)
+		},
+	},
+	{
+		name: "V",
+		args: []arg{coord("X"), coord("Y1"), coord("Y2"), colour()},
+		help: "Draws a vertical segment of colour C in column X between rows Y1 and Y2 (inclusive).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.SetMultiY(c.Coords[0], c.Coords[1], c.Coords[2], c.Char)
+		},
+	},
+	{
+		name: "H",
+		args: []arg{coord("X1"), coord("X2"), coord("Y"), colour()},
+		help: "Draws a horizontal segment of colour C in row Y between columns X1 and X2 (inclusive).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.SetMultiX(c.Coords[0], c.Coords[1], c.Coords[2], c.Char)
+		},
+	},
+	{
+		name: "D",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2"), colour()},
+		help: "Draws a straight line of colour C from (X1,Y1) to (X2,Y2) (inclusive).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Line(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Char)
+		},
+	},
+	{
+		name: "R",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2"), colour()},
+		help: "Draws the outline of a rectangle of colour C with opposite corners (X1,Y1) and (X2,Y2).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Rect(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Char)
+		},
+	},
+	{
+		name: "B",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2"), colour()},
+		help: "Draws a filled rectangle of colour C with opposite corners (X1,Y1) and (X2,Y2).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Box(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Char)
+		},
+	},
+	{
+		name: "O",
+		args: []arg{coord("X"), coord("Y"), number("R", "radius"), colour()},
+		help: "Draws the outline of a circle of colour C centred on (X,Y) with radius R.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Circle(c.Coords[0], c.Coords[1], c.Coords[2], c.Char)
+		},
+	},
+	{
+		name: "FO",
+		args: []arg{coord("X"), coord("Y"), number("R", "radius"), colour()},
+		help: "Draws a filled circle of colour C centred on (X,Y) with radius R.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.FilledCircle(c.Coords[0], c.Coords[1], c.Coords[2], c.Char)
+		},
+	},
+	{
+		name: "E",
+		args: []arg{coord("X"), coord("Y"), number("RX", "horizontal radius"), number("RY", "vertical radius"), colour()},
+		help: "Draws the outline of an ellipse of colour C centred on (X,Y) with radii RX and RY.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Ellipse(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Char)
+		},
+	},
+	{
+		name: "FE",
+		args: []arg{coord("X"), coord("Y"), number("RX", "horizontal radius"), number("RY", "vertical radius"), colour()},
+		help: "Draws a filled ellipse of colour C centred on (X,Y) with radii RX and RY.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.FilledEllipse(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Char)
+		},
+	},
+	{
+		name: "F",
+		args: []arg{coord("X"), coord("Y"), colour()},
+		help: "Fills the region containing pixel (X,Y) with colour C.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Fill(c.Coords[0], c.Coords[1], c.Char)
+		},
+	},
+	{
+		name: "Y",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2")},
+		help: "Copies the rectangle with opposite corners (X1,Y1) and (X2,Y2).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Copy(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3])
+		},
+	},
+	{
+		name: "P",
+		args: []arg{coord("X"), coord("Y")},
+		help: "Pastes the copied rectangle with its top left corner at (X,Y).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Paste(c.Coords[0], c.Coords[1])
+		},
+	},
+	{
+		name: "M",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2"), number("DX", "column offset"), number("DY", "row offset")},
+		help: "Moves the rectangle with opposite corners (X1,Y1) and (X2,Y2) by DX columns and DY rows, leaving white (O) behind, or transparent (.) above the base layer.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Move(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], c.Coords[4], c.Coords[5])
+		},
+	},
+	{
+		name: "RO",
+		args: []arg{number("D", "angle")},
+		help: "Rotates the image clockwise by D degrees, a multiple of 90.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Rotate(c.Coords[0])
+		},
+	},
+	{
+		name: "FH",
+		help: "Flips the image horizontally, mirroring it left to right.",
+		run: func(r *Runner, c Command) error {
+			r.editor.FlipHorizontal()
+			return nil
+		},
+	},
+	{
+		name: "FV",
+		help: "Flips the image vertically, mirroring it top to bottom.",
+		run: func(r *Runner, c Command) error {
+			r.editor.FlipVertical()
+			return nil
+		},
+	},
+	{
+		name: "T",
+		help: "Transposes the image, swapping its rows and columns.",
+		run: func(r *Runner, c Command) error {
+			r.editor.Transpose()
+			return nil
+		},
+	},
+	{
+		name: "Z",
+		args: []arg{number("M", "width"), number("N", "height"), optional(anchor())},
+		help: "Resizes the canvas to M x N, placing the image by anchor (TL, T, TR, L, C, R, BL, B or BR; default TL) and padding with white (O), or transparent (.) above the base layer.",
+		run: func(r *Runner, c Command) error {
+			return r.resize(c.Coords[0], c.Coords[1], c.Anchor)
+		},
+	},
+	{
+		name: "K",
+		args: []arg{number("factor", "factor")},
+		help: "Scales the image up by a whole number factor.",
+		run: func(r *Runner, c Command) error {
+			cols, rows := r.editor.Size()
+			return r.scale(cols*c.Coords[0], rows*c.Coords[0])
+		},
+	},
+	{
+		name: "K",
+		args: []arg{number("M", "width"), number("N", "height")},
+		help: "Scales the image to M x N, taking each pixel from the nearest one.",
+		run: func(r *Runner, c Command) error {
+			return r.scale(c.Coords[0], c.Coords[1])
+		},
+	},
+	{
+		name: "X",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2")},
+		help: "Crops the image to the rectangle with opposite corners (X1,Y1) and (X2,Y2).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Crop(c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3])
+		},
+	},
+	{
+		name: "X",
+		args: []arg{keyword("AUTO")},
+		help: "Crops the image to the smallest rectangle holding every pixel that is not white (O).",
+		run: func(r *Runner, c Command) error {
+			return r.editor.CropToContent()
+		},
+	},
+	{
+		name: "LN",
+		args: []arg{named("layer name")},
+		help: "Adds a new transparent layer on top of the others and draws on it.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.AddLayer(c.Name)
+		},
+	},
+	{
+		name: "LS",
+		args: []arg{named("layer name")},
+		help: "Selects the layer to draw on.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.SelectLayer(c.Name)
+		},
+	},
+	{
+		name: "LH",
+		args: []arg{named("layer name")},
+		help: "Hides a layer.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.HideLayer(c.Name, true)
+		},
+	},
+	{
+		name: "LV",
+		args: []arg{named("layer name")},
+		help: "Shows a hidden layer.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.HideLayer(c.Name, false)
+		},
+	},
+	{
+		name: "LO",
+		args: []arg{named("layer name"), number("N", "position")},
+		help: "Moves a layer to position N in the stack, counting up from 1 at the bottom.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.MoveLayer(c.Name, c.Coords[0])
+		},
+	},
+	{
+		name: "LM",
+		args: []arg{named("layer name")},
+		help: "Merges a layer into the one beneath it.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.MergeLayer(c.Name)
+		},
+	},
+	{
+		name: "C",
+		help: "Clears the layer being drawn on, setting all pixels to white (O), or transparent (.) above the base layer.",
+		run: func(r *Runner, c Command) error {
+			r.editor.Clear()
+			return nil
+		},
+	},
+	{
+		name: "U",
+		help: "Undoes the last change to the image.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Undo()
+		},
+	},
+	{
+		name: "R",
+		help: "Redoes the last undone change.",
+		run: func(r *Runner, c Command) error {
+			return r.editor.Redo()
+		},
+	},
+	{
+		name: "W",
+		args: []arg{path()},
+		help: "Writes the image to a file.",
+		run: func(r *Runner, c Command) error {
+			return r.save(c.Path)
+		},
+	},
+	{
+		name: "O",
+		args: []arg{path()},
+		help: "Opens an image previously written with W.",
+		run: func(r *Runner, c Command) error {
+			return r.load(c.Path)
+		},
+	},
+	{
+		name: "P",
+		args: []arg{path(), optional(number("scale", "scale"))},
+		help: "Exports the image as a PNG file, each pixel drawn as a scale x scale square (default 1).",
+		run: func(r *Runner, c Command) error {
+			scale := 1
+			if len(c.Coords) > 0 {
+				scale = c.Coords[0]
+			}
+			return r.export(c.Path, scale)
+		},
+	},
+	{
+		name: "S",
+		args: []arg{option("COLOR"), option("RULERS")},
+		help: "Shows the contents of the image, as coloured blocks if COLOR is given and with the columns and rows numbered if RULERS is.",
+		run: func(r *Runner, c Command) error {
+			return r.show(c)
+		},
+	},
+	{
+		name: "S",
+		args: []arg{coord("X1"), coord("Y1"), coord("X2"), coord("Y2"), option("COLOR"), option("RULERS")},
+		help: "Shows only the rectangle with opposite corners (X1,Y1) and (X2,Y2).",
+		run: func(r *Runner, c Command) error {
+			return r.show(c)
+		},
+	},
+}
+
+// Help lists every command with its syntax and what it does.
+func Help(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, a := range actions {
+		fmt.Fprintf(tw, "%s\t%s\n", a.usage(), a.help)
+	}
+	for _, k := range keywords {
+		fmt.Fprintf(tw, "%s\t%s\n", k.usage, k.help)
+	}
+
+	return tw.Flush()
+}
+
+// Names lists the name of every command, in alphabetical order.
+func Names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, a := range actions {
+		if !seen[a.name] {
+			seen[a.name] = true
+			names = append(names, a.name)
+		}
+	}
+	for _, k := range keywords {
+		names = append(names, k.name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// parse matches a line of input against the command table, returning the
+// parsed command and the action that runs it.
+func parse(text []string) (Command, action, error) {
+	name, given := strings.ToUpper(text[0]), text[1:]
+
+	var candidates []action
+	for _, a := range actions {
+		if a.name == name {
+			candidates = append(candidates, a)
+		}
+	}
+	if len(candidates) == 0 {
+		return Command{}, action{}, &ParseError{errors.New("invalid action")}
+	}
+
+	// Of the forms that fit but fail, the one that got furthest through its
+	// arguments is most likely the one meant.
+	var (
+		bestErr error
+		best    int
+	)
+	for _, a := range candidates {
+		if !a.fits(len(given)) {
+			continue
+		}
+
+		command, failed, err := a.convert(given)
+		if err == nil {
+			return command, a, nil
+		}
+		if bestErr == nil || failed > best {
+			bestErr, best = err, failed
+		}
+	}
+	switch {
+	case errors.Is(bestErr, ErrArgumentRange):
+		return Command{}, action{}, bestErr
+	case bestErr != nil:
+		return Command{}, action{}, &ParseError{bestErr}
+	}
+
+	expected := make([]string, len(candidates))
+	for i, a := range candidates {
+		expected[i] = describe(a.args)
+	}
+
+	return Command{}, action{}, &ParseError{fmt.Errorf("%s expects %s, got %d", name, strings.Join(expected, ", or "), len(given))}
+}
+
+func (a action) fits(n int) bool {
+	required := 0
+	for _, arg := range a.args {
+		if !arg.optional {
+			required++
+		}
+	}
+
+	return n >= required && n <= len(a.args)
+}
+
+// convert turns the given words into a command, or reports the index of the
+// first that does not fit.
+func (a action) convert(given []string) (Command, int, error) {
+	command := Command{Action: a.name, Coords: []int{}, Anchor: "TL"}
+
+	for i, text := range given {
+		switch a.args[i].kind {
+		case coordArg, numberArg:
+			n, err := eval(text)
+			if err != nil {
+				return Command{}, i, err
+			}
+			if n < -maxArgument || n > maxArgument {
+				return Command{}, i, fmt.Errorf("%w, got %d", ErrArgumentRange, n)
+			}
+			command.Coords = append(command.Coords, n)
+		case colourArg:
+			command.Char = strings.ToUpper(text)
+		case pathArg:
+			command.Path = text
+		case anchorArg:
+			command.Anchor = strings.ToUpper(text)
+			if _, ok := anchors[command.Anchor]; !ok {
+				return Command{}, i, fmt.Errorf("unknown anchor '%s', use TL, T, TR, L, C, R, BL, B or BR", text)
+			}
+		case nameArg:
+			command.Name = text
+		case keywordArg:
+			if strings.ToUpper(text) != a.args[i].name {
+				return Command{}, i, fmt.Errorf("expected %s, got '%s'", a.args[i].name, text)
+			}
+			command.Keywords = append(command.Keywords, a.args[i].name)
+		case optionArg:
+			word, err := a.option(text, command.Keywords)
+			if err != nil {
+				return Command{}, i, err
+			}
+			command.Keywords = append(command.Keywords, word)
+		}
+	}
+
+	return command, 0, nil
+}
+
+// option matches a word against the action's options, any of which may
+// come in any order but only once.
+func (a action) option(text string, given []string) (string, error) {
+	word := strings.ToUpper(text)
+
+	var options []string
+	for _, arg := range a.args {
+		if arg.kind == optionArg {
+			options = append(options, arg.name)
+		}
+	}
+
+	for _, name := range options {
+		if name != word {
+			continue
+		}
+		for _, g := range given {
+			if g == word {
+				return "", fmt.Errorf("%s given more than once", word)
+			}
+		}
+		return word, nil
+	}
+
+	return "", fmt.Errorf("expected %s, got '%s'", strings.Join(options, " or "), text)
+}
+
+// usage is the action's syntax, with optional arguments in brackets, as in
+// "P path [scale]".
+func (a action) usage() string {
+	parts := []string{a.name}
+	for _, arg := range a.args {
+		if arg.optional {
+			parts = append(parts, "["+arg.name+"]")
+		} else {
+			parts = append(parts, arg.name)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// describe spells out what an action expects, as in "3 coordinates and a
+// colour".
+func describe(args []arg) string {
+	if len(args) == 0 {
+		return "no arguments"
+	}
+
+	coords := 0
+	for _, a := range args {
+		if a.kind == coordArg && !a.optional {
+			coords++
+		}
+	}
+
+	var parts []string
+	switch {
+	case coords == 1:
+		parts = append(parts, "a coordinate")
+	case coords > 1:
+		parts = append(parts, fmt.Sprintf("%d coordinates", coords))
+	}
+
+	for _, a := range args {
+		switch {
+		case a.kind == coordArg && !a.optional:
+		case a.optional:
+			parts = append(parts, "an optional "+a.noun)
+		case a.kind == keywordArg:
+			parts = append(parts, a.noun)
+		default:
+			parts = append(parts, "a "+a.noun)
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
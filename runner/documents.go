@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// defaultDocument names the image a runner is created with.
+const defaultDocument = "main"
+
+// SetEditorFactory gives the runner a way to make editors for the new
+// documents created by "I name M N".
+func (r *Runner) SetEditorFactory(factory func() ImageEditor) {
+	r.factory = factory
+}
+
+func (r *Runner) createDocument(name string, cols, rows int) error {
+	if err := r.checkSize(cols, rows); err != nil {
+		return err
+	}
+
+	ed, ok := r.editors[name]
+	if !ok {
+		if r.factory == nil {
+			return errors.New("new documents cannot be created here")
+		}
+		ed = r.factory()
+		r.editors[name] = ed
+	}
+	ed.CreateImage(cols, rows)
+
+	return r.useDocument(name)
+}
+
+func (r *Runner) useDocument(name string) error {
+	ed, ok := r.editors[name]
+	if !ok {
+		return fmt.Errorf("no document named '%s'", name)
+	}
+
+	r.current, r.editor = name, ed
+
+	return nil
+}
+
+func (r *Runner) closeDocument(name string) error {
+	if _, ok := r.editors[name]; !ok {
+		return fmt.Errorf("no document named '%s'", name)
+	}
+	if name == r.current {
+		return fmt.Errorf("cannot close '%s' while it is in use", name)
+	}
+
+	delete(r.editors, name)
+
+	return nil
+}
+
+// listDocuments prints each document's name and size, marking the one in
+// use.
+func (r *Runner) listDocuments() {
+	names := make([]string, 0, len(r.editors))
+	for name := range r.editors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mark := " "
+		if name == r.current {
+			mark = "*"
+		}
+		cols, rows := r.editors[name].Size()
+		fmt.Fprintf(r.out, "%s %s %dx%d\n", mark, name, cols, rows)
+	}
+}
+
+func (r *Runner) copyDocument(name string, x, y int) error {
+	ed, ok := r.editors[name]
+	if !ok {
+		return fmt.Errorf("no document named '%s'", name)
+	}
+
+	return r.editor.PastePicture(ed.Picture(), x, y)
+}
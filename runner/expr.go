@@ -0,0 +1,185 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errDivideByZero = errors.New("division by zero")
+	errOverflow     = errors.New("overflow")
+)
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// maxLiteral keeps numbers well clear of overflow; nothing in an image gets
+// near it.
+const maxLiteral = 1 << 30
+
+// eval works out an integer expression such as "3", "-2" or "(x+1)*2" once
+// its variables have been expanded. It understands + - * / % and brackets,
+// with the usual precedence.
+func eval(text string) (int, error) {
+	p := &exprParser{text: text}
+
+	n, err := p.sum()
+	if err == nil && p.pos < len(p.text) {
+		err = errors.New("unexpected input")
+	}
+	switch {
+	case err == errDivideByZero:
+		return 0, fmt.Errorf("division by zero in '%s'", text)
+	case err == errOverflow:
+		return 0, fmt.Errorf("integer overflow in '%s'", text)
+	case err != nil:
+		return 0, fmt.Errorf("could not parse non-integer '%s'", text)
+	}
+
+	return n, nil
+}
+
+type exprParser struct {
+	text string
+	pos  int
+}
+
+func (p *exprParser) sum() (int, error) {
+	n, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.pos < len(p.text) {
+		op := p.text[p.pos]
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+
+		m, err := p.product()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			n, err = add(n, m)
+		} else {
+			n, err = subtract(n, m)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+func (p *exprParser) product() (int, error) {
+	n, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.pos < len(p.text) {
+		op := p.text[p.pos]
+		if op != '*' && op != '/' && op != '%' {
+			break
+		}
+		p.pos++
+
+		m, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case op == '*':
+			if n, err = multiply(n, m); err != nil {
+				return 0, err
+			}
+		case m == 0:
+			return 0, errDivideByZero
+		case op == '/':
+			if n == minInt && m == -1 {
+				return 0, errOverflow
+			}
+			n /= m
+		default:
+			n %= m
+		}
+	}
+
+	return n, nil
+}
+
+func (p *exprParser) factor() (int, error) {
+	if p.pos >= len(p.text) {
+		return 0, errors.New("unexpected end")
+	}
+
+	switch c := p.text[p.pos]; {
+	case c == '-' || c == '+':
+		p.pos++
+		n, err := p.factor()
+		if err != nil || c == '+' {
+			return n, err
+		}
+		return negate(n)
+	case c == '(':
+		p.pos++
+		n, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		if p.pos >= len(p.text) || p.text[p.pos] != ')' {
+			return 0, errors.New("missing )")
+		}
+		p.pos++
+		return n, nil
+	case c >= '0' && c <= '9':
+		n := 0
+		for p.pos < len(p.text) && p.text[p.pos] >= '0' && p.text[p.pos] <= '9' {
+			n = n*10 + int(p.text[p.pos]-'0')
+			p.pos++
+			if n > maxLiteral {
+				return 0, errors.New("number too large")
+			}
+		}
+		return n, nil
+	}
+
+	return 0, errors.New("unexpected character")
+}
+
+func add(a, b int) (int, error) {
+	if b > 0 && a > maxInt-b || b < 0 && a < minInt-b {
+		return 0, errOverflow
+	}
+	return a + b, nil
+}
+
+func subtract(a, b int) (int, error) {
+	if b < 0 && a > maxInt+b || b > 0 && a < minInt+b {
+		return 0, errOverflow
+	}
+	return a - b, nil
+}
+
+func multiply(a, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	n := a * b
+	if n/b != a || a == -1 && b == minInt || b == -1 && a == minInt {
+		return 0, errOverflow
+	}
+	return n, nil
+}
+
+func negate(n int) (int, error) {
+	if n == minInt {
+		return 0, errOverflow
+	}
+	return -n, nil
+}
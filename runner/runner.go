@@ -2,27 +2,130 @@ package runner
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"strconv"
+	"os"
 	"strings"
 )
 
 const (
 	MinValue = 1
-	MaxValue = 1024
+	MaxValue = 65536
+
+	// defaultColours is how many colours S COLOR draws with, unless told the
+	// terminal can show more.
+	defaultColours = 256
+
+	// maxArgument bounds coordinates and other numbers given to commands.
+	// It leaves room for shapes centred off the largest image, while
+	// keeping the work one command does in proportion to the image.
+	maxArgument = 2 * MaxValue
 )
 
+var errFilesDisabled = errors.New("file access is disabled")
+
+// ErrImageSize is returned when an image is created outside the size limits.
+var ErrImageSize = fmt.Errorf("image axis out of range: %d <= M,N <= %d", MinValue, MaxValue)
+
+// ErrArgumentRange is returned when a command is given a number too far from
+// any image to be a coordinate.
+var ErrArgumentRange = fmt.Errorf("number out of range: -%d <= n <= %d", maxArgument, maxArgument)
+
+// ErrImagePixels is returned when an image would have more pixels than the
+// runner allows.
+var ErrImagePixels = errors.New("image has too many pixels")
+
+// ErrBudget is returned once a runner has run every command its budget
+// allows.
+var ErrBudget = errors.New("command budget used up, run fewer commands")
+
 type Runner struct {
 	scanner *bufio.Scanner
 	out     io.Writer
 	editor  ImageEditor
+	editors map[string]ImageEditor
+	current string
+	factory func() ImageEditor
+	source  string
+	line    int
+	strict  bool
+	noFiles bool
+	colour  bool
+	colours int
+	vars    map[string]string
+	macros  map[string]macro
+	block   *block
+	calls   int
+	budget  int
+	pixels  int
+}
+
+// Error is a failed command, along with where it was read from when the
+// runner has been given a source name.
+type Error struct {
+	Source string
+	Line   int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Source == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s:%d: %s", e.Source, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is a line that could not be understood as a command.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// FileError is a failure to read or write a file named by a command.
+type FileError struct {
+	Err error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
 }
 
 type Command struct {
-	Action string
-	Coords []int
-	Char   string
+	Action   string
+	Coords   []int
+	Char     string
+	Path     string
+	Anchor   string
+	Name     string
+	Keywords []string
+}
+
+// has reports whether the command was given the keyword.
+func (c Command) has(keyword string) bool {
+	for _, k := range c.Keywords {
+		if k == keyword {
+			return true
+		}
+	}
+
+	return false
 }
 
 //go:generate counterfeiter . ImageEditor
@@ -31,104 +134,478 @@ type ImageEditor interface {
 	Set(x, y int, char string) error
 	SetMultiY(x, y1, y2 int, char string) error
 	SetMultiX(x1, x2, y int, char string) error
-	Pretty() string
+	Line(x1, y1, x2, y2 int, char string) error
+	Rect(x1, y1, x2, y2 int, char string) error
+	Box(x1, y1, x2, y2 int, char string) error
+	Circle(x, y, r int, char string) error
+	FilledCircle(x, y, r int, char string) error
+	Ellipse(x, y, rx, ry int, char string) error
+	FilledEllipse(x, y, rx, ry int, char string) error
+	Fill(x, y int, char string) error
+	Copy(x1, y1, x2, y2 int) error
+	Paste(x, y int) error
+	Move(x1, y1, x2, y2, dx, dy int) error
+	Rotate(degrees int) error
+	FlipHorizontal()
+	FlipVertical()
+	Transpose()
+	Resize(cols, rows, dx, dy int)
+	Scale(cols, rows int)
+	Crop(x1, y1, x2, y2 int) error
+	CropToContent() error
+	AddLayer(name string) error
+	SelectLayer(name string) error
+	HideLayer(name string, hidden bool) error
+	MoveLayer(name string, position int) error
+	MergeLayer(name string) error
+	Picture() interface{}
+	PastePicture(picture interface{}, x, y int) error
+	Render(w io.Writer) error
+	RenderRegion(w io.Writer, x1, y1, x2, y2 int, rulers bool, colours int) error
 	Clear()
+	Undo() error
+	Redo() error
+	Save(w io.Writer) error
+	Load(r io.Reader) error
+	PNG(w io.Writer, scale int) error
+	Size() (cols, rows int)
+}
+
+func New(reader *bufio.Scanner, writer io.Writer, ed ImageEditor) *Runner {
+	return &Runner{
+		scanner: reader,
+		out:     writer,
+		editor:  ed,
+		editors: map[string]ImageEditor{defaultDocument: ed},
+		current: defaultDocument,
+		colours: defaultColours,
+		vars:    map[string]string{},
+		macros:  map[string]macro{},
+		budget:  -1,
+	}
 }
 
-func New(reader *bufio.Scanner, writer io.Writer, ed ImageEditor) Runner {
-	return Runner{scanner: reader, out: writer, editor: ed}
+// SetBudget limits how many more commands the runner will run, counting each
+// command run inside a REPEAT or macro and each pass of a REPEAT, so that a
+// long loop fails with ErrBudget instead of running on. A negative budget
+// removes the limit, as it is by default.
+func (r *Runner) SetBudget(steps int) {
+	r.budget = steps
 }
 
-func (r Runner) ProcessImageSize() error {
-	r.scanner.Scan()
-	text := strings.Split(r.scanner.Text(), " ")
+// spend takes one step from the budget.
+func (r *Runner) spend() error {
+	if r.budget < 0 {
+		return nil
+	}
+	if r.budget == 0 {
+		return ErrBudget
+	}
+	r.budget--
 
-	if strings.ToUpper(text[0]) != "I" {
-		return fmt.Errorf("unrecognised command '%s', use 'I' for Image initialisation", text[0])
+	return nil
+}
+
+// SetMaxPixels limits how many pixels an image may have, so that no one
+// command can paint or print too much. Zero, the default, removes the limit.
+func (r *Runner) SetMaxPixels(pixels int) {
+	r.pixels = pixels
+}
+
+// SetStrict makes ProcessEditActions stop at the first failed command and
+// return its error, rather than printing it and carrying on.
+func (r *Runner) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// SetInput makes the runner read from a new input, counting its lines from
+// the start. Images, documents, variables and macros carry over.
+func (r *Runner) SetInput(reader *bufio.Scanner) {
+	r.scanner = reader
+	r.line = 0
+}
+
+// SetColour makes S draw each pixel as a coloured block, as S COLOR does,
+// rather than as its letter.
+func (r *Runner) SetColour(colour bool) {
+	r.colour = colour
+}
+
+// SetColours sets how many colours the terminal can show, 256 unless it is
+// set higher for a terminal with truecolor.
+func (r *Runner) SetColours(colours int) {
+	r.colours = colours
+}
+
+// DisableFiles makes commands that read or write files fail, for runners
+// taking commands from untrusted callers.
+func (r *Runner) DisableFiles() {
+	r.noFiles = true
+}
+
+// SetSource names the input, such as a script's file name, so that errors
+// report the name and line number of the command that failed.
+func (r *Runner) SetSource(name string) {
+	r.source = name
+}
+
+// ProcessImageSize runs the image command that starts a script, along with
+// any SET, DEF and REPEAT commands before it, so that the size may be given
+// by variables.
+func (r *Runner) ProcessImageSize() error {
+	var text []string
+	for {
+		var ok bool
+		if text, ok = r.next(); !ok {
+			if err := r.Flush(); err != nil {
+				return err
+			}
+			return r.error(&ParseError{errors.New("missing image command, use 'I' for Image initialisation")})
+		}
+
+		if r.block == nil && !prelude(text[0]) {
+			break
+		}
+		if err := r.execute(text); err != nil {
+			return r.error(err)
+		}
 	}
 
-	axes, err := translateInts(text[1:])
+	text, err := r.expand(text)
 	if err != nil {
-		return err
+		return r.error(err)
 	}
-	xAxis, yAxis := axes[0], axes[1]
 
-	if !valid(xAxis) || !valid(yAxis) {
-		return fmt.Errorf("image axis out of range: %d <= M,N <= %d", MinValue, MaxValue)
+	if strings.ToUpper(text[0]) != "I" {
+		return r.error(&ParseError{fmt.Errorf("unrecognised command '%s', use 'I' for Image initialisation", text[0])})
 	}
 
-	r.editor.CreateImage(xAxis, yAxis)
+	command, action, err := parse(text)
+	if err != nil {
+		return r.error(err)
+	}
+
+	if err := action.run(r, command); err != nil {
+		return r.error(err)
+	}
 
 	return nil
 }
 
-func (r Runner) ProcessEditActions() {
+func (r *Runner) ProcessEditActions() error {
 	for {
-		r.scanner.Scan()
-		text := strings.Split(r.scanner.Text(), " ")
-
-		if text[0] == "" {
+		text, ok := r.next()
+		if !ok {
 			break
 		}
 
-		command := Command{Action: strings.ToUpper(text[0])}
-
-		if len(text) > 1 {
-			coords, err := translateInts(text[1 : len(text)-1])
-			if err != nil {
-				fmt.Fprintln(r.out, err)
-				continue
+		if err := r.execute(text); err != nil {
+			if err := r.report(err); err != nil {
+				return err
 			}
-			command.Coords = coords
-			command.Char = strings.ToUpper(text[len(text)-1])
-		}
-
-		if err := r.applyAction(command); err != nil {
-			fmt.Fprintln(r.out, err)
 			continue
 		}
 	}
-}
 
-func (r Runner) applyAction(command Command) error {
-	switch command.Action {
-	case "L":
-		if err := r.editor.Set(command.Coords[0], command.Coords[1], command.Char); err != nil {
+	if err := r.Flush(); err != nil {
+		if err := r.report(err); err != nil {
 			return err
 		}
-	case "V":
-		if err := r.editor.SetMultiY(command.Coords[0], command.Coords[1], command.Coords[2], command.Char); err != nil {
-			return err
-		}
-	case "H":
-		if err := r.editor.SetMultiX(command.Coords[0], command.Coords[1], command.Coords[2], command.Char); err != nil {
-			return err
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return &FileError{err}
+	}
+
+	return nil
+}
+
+// Execute runs a single line of input, for callers such as an interactive
+// shell that read their own input.
+func (r *Runner) Execute(line string) error {
+	r.line++
+
+	text := tokenize(line)
+	if len(text) == 0 {
+		return nil
+	}
+
+	if err := r.execute(text); err != nil {
+		return r.error(err)
+	}
+
+	return nil
+}
+
+// Flush ends the input, for callers that feed lines to Execute. A REPEAT or
+// DEF still waiting for its END is dropped and returned as an error.
+func (r *Runner) Flush() error {
+	if r.block == nil {
+		return nil
+	}
+
+	b := r.block
+	r.block = nil
+	defer r.at(b.header.line)()
+
+	return r.error(&ParseError{fmt.Errorf("%s without END", strings.ToUpper(b.header.words[0]))})
+}
+
+// Pending reports whether a REPEAT or DEF block is still waiting for its
+// END.
+func (r *Runner) Pending() bool {
+	return r.block != nil
+}
+
+// Editor is the editor of the document in use.
+func (r *Runner) Editor() ImageEditor {
+	return r.editor
+}
+
+// Export writes the current image to a PNG file.
+func (r *Runner) Export(path string, scale int) error {
+	return r.export(path, scale)
+}
+
+// Size is the width and height of the current image.
+func (r *Runner) Size() (int, int) {
+	return r.editor.Size()
+}
+
+// execute runs one line of input, or adds it to the block being read when a
+// REPEAT or DEF is waiting for its END.
+func (r *Runner) execute(text []string) error {
+	if r.block != nil {
+		return r.collect(text)
+	}
+
+	switch strings.ToUpper(text[0]) {
+	case "REPEAT", "DEF":
+		r.block = &block{header: statement{line: r.line, words: text}}
+		return nil
+	case "END":
+		return &ParseError{errors.New("END without REPEAT or DEF")}
+	}
+
+	if err := r.spend(); err != nil {
+		return err
+	}
+
+	text, err := r.expand(text)
+	if err != nil {
+		return err
+	}
+
+	name := strings.ToUpper(text[0])
+	if name == "SET" {
+		return r.setVariable(text[1:])
+	}
+	if m, ok := r.macros[name]; ok {
+		return r.call(name, m, text[1:])
+	}
+
+	command, action, err := parse(text)
+	if err != nil {
+		return err
+	}
+
+	return action.run(r, command)
+}
+
+// prelude reports whether a command may come before the image command.
+func prelude(name string) bool {
+	switch strings.ToUpper(name) {
+	case "SET", "DEF", "REPEAT", "END":
+		return true
+	}
+
+	return false
+}
+
+// next reads the words of the next line holding a command, skipping blank
+// and comment lines. It reports false at the end of the input.
+func (r *Runner) next() ([]string, bool) {
+	for r.scanner.Scan() {
+		r.line++
+
+		if text := tokenize(r.scanner.Text()); len(text) > 0 {
+			return text, true
 		}
-	case "S":
-		fmt.Fprintln(r.out, r.editor.Pretty())
-	case "C":
-		r.editor.Clear()
+	}
+
+	return nil, false
+}
+
+// report prints the error of a failed command, unless the runner is strict,
+// in which case it is returned to stop processing.
+func (r *Runner) report(err error) error {
+	if r.strict {
+		return r.error(err)
+	}
+
+	fmt.Fprintln(r.out, r.error(err))
+
+	return nil
+}
+
+func (r *Runner) error(err error) error {
+	// Errors from within a block already name the line they came from.
+	var located *Error
+	if errors.As(err, &located) {
+		return err
+	}
+
+	return &Error{Source: r.source, Line: r.line, Err: err}
+}
+
+func (r *Runner) createImage(xAxis, yAxis int) error {
+	if err := r.checkSize(xAxis, yAxis); err != nil {
+		return err
+	}
+
+	r.editor.CreateImage(xAxis, yAxis)
+
+	return nil
+}
+
+func (r *Runner) resize(cols, rows int, anchor string) error {
+	if err := r.checkSize(cols, rows); err != nil {
+		return err
+	}
+
+	oldCols, oldRows := r.editor.Size()
+	share := anchors[anchor]
+	r.editor.Resize(cols, rows, (cols-oldCols)*share[0]/2, (rows-oldRows)*share[1]/2)
+
+	return nil
+}
+
+func (r *Runner) scale(cols, rows int) error {
+	if err := r.checkSize(cols, rows); err != nil {
+		return err
+	}
+
+	r.editor.Scale(cols, rows)
+
+	return nil
+}
+
+// show prints the image, or the rectangle given by four coordinates, in
+// colour and with rulers if asked for.
+func (r *Runner) show(c Command) error {
+	rulers := c.has("RULERS")
+
+	colours := 0
+	if r.colour || c.has("COLOR") {
+		colours = r.colours
+	}
+
+	var err error
+	switch {
+	case len(c.Coords) == 4:
+		err = r.editor.RenderRegion(r.out, c.Coords[0], c.Coords[1], c.Coords[2], c.Coords[3], rulers, colours)
+	case rulers || colours != 0:
+		cols, rows := r.editor.Size()
+		err = r.editor.RenderRegion(r.out, 1, 1, cols, rows, rulers, colours)
 	default:
-		fmt.Fprintln(r.out, "invalid action")
+		err = r.editor.Render(r.out)
+	}
+	if err != nil {
+		return err
 	}
 
+	fmt.Fprintln(r.out)
+
 	return nil
 }
 
-func translateInts(axStr []string) ([]int, error) {
-	axes := []int{}
+func (r *Runner) save(path string) error {
+	if r.noFiles {
+		return &FileError{errFilesDisabled}
+	}
 
-	for _, a := range axStr {
-		axis, err := strconv.Atoi(a)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse non-integer '%s'", a)
-		}
-		axes = append(axes, axis)
+	f, err := os.Create(path)
+	if err != nil {
+		return &FileError{err}
+	}
+
+	if err := r.editor.Save(f); err != nil {
+		f.Close()
+		return &FileError{err}
+	}
+
+	if err := f.Close(); err != nil {
+		return &FileError{err}
+	}
+
+	return nil
+}
+
+func (r *Runner) export(path string, scale int) error {
+	if r.noFiles {
+		return &FileError{errFilesDisabled}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return &FileError{err}
 	}
 
-	return axes, nil
+	if err := r.editor.PNG(f, scale); err != nil {
+		f.Close()
+		return &FileError{err}
+	}
+
+	if err := f.Close(); err != nil {
+		return &FileError{err}
+	}
+
+	return nil
+}
+
+func (r *Runner) load(path string) error {
+	if r.noFiles {
+		return &FileError{errFilesDisabled}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return &FileError{err}
+	}
+	defer f.Close()
+
+	// Load into a new editor where the runner can make one, so that a file
+	// outside the size limits leaves the document as it was.
+	ed := r.editor
+	if r.factory != nil {
+		ed = r.factory()
+	}
+
+	if err := ed.Load(f); err != nil {
+		return &FileError{err}
+	}
+	if err := r.checkSize(ed.Size()); err != nil {
+		return err
+	}
+
+	r.editor = ed
+	r.editors[r.current] = ed
+
+	return nil
+}
+
+// checkSize reports whether an image of the given size is allowed.
+func (r *Runner) checkSize(cols, rows int) error {
+	if !valid(cols) || !valid(rows) {
+		return ErrImageSize
+	}
+	if r.pixels > 0 && cols > r.pixels/rows {
+		return fmt.Errorf("%w: %dx%d is more than %d", ErrImagePixels, cols, rows, r.pixels)
+	}
+
+	return nil
 }
 
 func valid(axis int) bool {
-	return axis > MinValue && axis < MaxValue
+	return axis >= MinValue && axis <= MaxValue
 }
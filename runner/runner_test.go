@@ -4,6 +4,10 @@ import (
 	"bufio"
 	"errors"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/mo-work/go-technical-test-for-claudia/runner"
 	"github.com/mo-work/go-technical-test-for-claudia/runner/runnerfakes"
@@ -16,7 +20,7 @@ var _ = Describe("Runner", func() {
 	var (
 		inBuf           *gbytes.Buffer
 		outBuf          *gbytes.Buffer
-		r               runner.Runner
+		r               *runner.Runner
 		fakeImageEditor *runnerfakes.FakeImageEditor
 	)
 
@@ -51,6 +55,57 @@ var _ = Describe("Runner", func() {
 			Expect(cols).To(Equal(7))
 		})
 
+		It("skips blank lines and comments before the image command", func() {
+			_, err := io.WriteString(inBuf, "# a picture\n\n  \nI 5 7 # five by seven\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.ProcessImageSize()).To(Succeed())
+			Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(1))
+		})
+
+		It("runs SET, DEF and REPEAT before the image command", func() {
+			_, err := io.WriteString(inBuf, "SET n 10\nDEF dot x y\nL $x $y A\nEND\nREPEAT 2 i\nSET n $n+$i\nEND\nI $n $n*2\ndot 1 1\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.ProcessImageSize()).To(Succeed())
+			Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(1))
+			cols, rows := fakeImageEditor.CreateImageArgsForCall(0)
+			Expect(cols).To(Equal(13))
+			Expect(rows).To(Equal(26))
+
+			Expect(r.ProcessEditActions()).To(Succeed())
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+		})
+
+		Context("if a block before the image command has no END", func() {
+			It("fails", func() {
+				_, err := io.WriteString(inBuf, "DEF dot x y\nI 5 5\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(MatchError("DEF without END"))
+				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if the input has no image command", func() {
+			It("fails", func() {
+				_, err := io.WriteString(inBuf, "# nothing here\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(MatchError("missing image command, use 'I' for Image initialisation"))
+			})
+		})
+
+		Context("if an axis is missing", func() {
+			It("fails", func() {
+				_, err := io.WriteString(inBuf, "I 5")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(MatchError("I expects a width and a height, or a document name, a width and a height, got 1"))
+				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("if the argument for the x azis cannot be translated into an integer", func() {
 			It("fails", func() {
 				_, err := io.WriteString(inBuf, "I x 7")
@@ -71,12 +126,22 @@ var _ = Describe("Runner", func() {
 			})
 		})
 		//
+		It("accepts sizes at the limits", func() {
+			_, err := io.WriteString(inBuf, "I 1 65536")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.ProcessImageSize()).To(Succeed())
+			cols, rows := fakeImageEditor.CreateImageArgsForCall(0)
+			Expect(cols).To(Equal(1))
+			Expect(rows).To(Equal(65536))
+		})
+
 		Context("if the argument for the x axis size is less than the min value", func() {
 			It("fails", func() {
 				_, err := io.WriteString(inBuf, "I 0 7")
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 1024"))
+				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 65536"))
 				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
 			})
 		})
@@ -86,32 +151,119 @@ var _ = Describe("Runner", func() {
 				_, err := io.WriteString(inBuf, "I 7 0")
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 1024"))
+				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 65536"))
 				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
 			})
 		})
 
 		Context("if the argument for the x axis size is greater than the max value", func() {
 			It("fails", func() {
-				_, err := io.WriteString(inBuf, "I 1025 7")
+				_, err := io.WriteString(inBuf, "I 65537 7")
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 1024"))
+				err = r.ProcessImageSize()
+				Expect(err).To(MatchError("image axis out of range: 1 <= M,N <= 65536"))
+				Expect(errors.Is(err, runner.ErrImageSize)).To(BeTrue())
 				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
 			})
 		})
 
 		Context("if the argument for the y axis size is greater than the max value", func() {
 			It("fails", func() {
-				_, err := io.WriteString(inBuf, "I 7 1025")
+				_, err := io.WriteString(inBuf, "I 7 65537")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 65536"))
+				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if the image would have more pixels than the runner allows", func() {
+			It("fails", func() {
+				r.SetMaxPixels(100)
+				_, err := io.WriteString(inBuf, "I 11 10")
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(r.ProcessImageSize()).To(MatchError("image axis out of range: 1 <= M,N <= 1024"))
+				err = r.ProcessImageSize()
+				Expect(err).To(MatchError("image has too many pixels: 11x10 is more than 100"))
+				Expect(errors.Is(err, runner.ErrImagePixels)).To(BeTrue())
 				Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(0))
+
+				Expect(r.Execute("I 10 10")).To(Succeed())
+				fakeImageEditor.SizeReturns(10, 10)
+				Expect(errors.Is(r.Execute("Z 10 11"), runner.ErrImagePixels)).To(BeTrue())
+				Expect(errors.Is(r.Execute("K 20 10"), runner.ErrImagePixels)).To(BeTrue())
+				Expect(fakeImageEditor.ResizeCallCount()).To(Equal(0))
+				Expect(fakeImageEditor.ScaleCallCount()).To(Equal(0))
 			})
 		})
 	})
 
+	Describe("Execute", func() {
+		It("runs a single line", func() {
+			Expect(r.Execute("l 1 3 a")).To(Succeed())
+
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+			x, y, char := fakeImageEditor.SetArgsForCall(0)
+			Expect(x).To(Equal(1))
+			Expect(y).To(Equal(3))
+			Expect(char).To(Equal("A"))
+		})
+
+		It("returns the error of a failed command", func() {
+			fakeImageEditor.SetReturns(errors.New("EXPLODE"))
+			Expect(r.Execute("L 1 3 A")).To(MatchError("EXPLODE"))
+		})
+
+		It("ignores blank and comment lines", func() {
+			Expect(r.Execute("   ")).To(Succeed())
+			Expect(r.Execute("# just a note")).To(Succeed())
+			Expect(fakeImageEditor.Invocations()).To(BeEmpty())
+		})
+
+		It("counts lines for errors", func() {
+			r.SetSource("shell")
+			Expect(r.Execute("C")).To(Succeed())
+			Expect(r.Execute("L 1")).To(MatchError("shell:2: L expects 2 coordinates and a colour, got 1"))
+		})
+	})
+
+	Describe("Size", func() {
+		It("is the size of the editor's image", func() {
+			fakeImageEditor.SizeReturns(4, 6)
+
+			cols, rows := r.Size()
+			Expect(cols).To(Equal(4))
+			Expect(rows).To(Equal(6))
+		})
+	})
+
+	Describe("Help", func() {
+		It("lists the syntax and description of every command", func() {
+			Expect(runner.Help(outBuf)).To(Succeed())
+			Expect(outBuf).To(gbytes.Say(`I M N\s+Creates a new M x N image`))
+			Expect(outBuf).To(gbytes.Say(`V X Y1 Y2 C\s+Draws a vertical segment`))
+			Expect(outBuf).To(gbytes.Say(`S \[COLOR\] \[RULERS\]\s+Shows the contents of the image`))
+			Expect(outBuf).To(gbytes.Say(`S X1 Y1 X2 Y2 \[COLOR\] \[RULERS\]\s+Shows only the rectangle`))
+		})
+	})
+
+	Describe("Names", func() {
+		It("lists each command name once, in order", func() {
+			names := runner.Names()
+			Expect(names).To(ContainElement("FO"))
+			Expect(sort.StringsAreSorted(names)).To(BeTrue())
+
+			count := 0
+			for _, name := range names {
+				if name == "R" {
+					count++
+				}
+			}
+			Expect(count).To(Equal(1))
+		})
+	})
+
 	Describe("ProcessEditActions", func() {
 		It("forwards Set instructions to the editor", func() {
 			_, err := io.WriteString(inBuf, "L 1 3 A")
@@ -199,45 +351,1317 @@ var _ = Describe("Runner", func() {
 			})
 		})
 
-		It("forwards Show instructions to the editor", func() {
-			_, err := io.WriteString(inBuf, "S")
+		It("forwards Line instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "D 1 2 5 4 Q")
 			Expect(err).NotTo(HaveOccurred())
 
 			r.ProcessEditActions()
 
-			Expect(fakeImageEditor.PrettyCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.LineCallCount()).To(Equal(1))
+			x1, y1, x2, y2, char := fakeImageEditor.LineArgsForCall(0)
+			Expect(x1).To(Equal(1))
+			Expect(y1).To(Equal(2))
+			Expect(x2).To(Equal(5))
+			Expect(y2).To(Equal(4))
+			Expect(char).To(Equal("Q"))
 		})
 
-		It("forwards Clear instructions to the editor", func() {
-			_, err := io.WriteString(inBuf, "C")
+		Context("if calling Line on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.LineReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "D 1 2 5 4 Q")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.LineCallCount()).To(Equal(1))
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Rect instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "R 1 2 5 4 Q")
 			Expect(err).NotTo(HaveOccurred())
 
 			r.ProcessEditActions()
 
-			Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.RectCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.RedoCallCount()).To(Equal(0))
+			x1, y1, x2, y2, char := fakeImageEditor.RectArgsForCall(0)
+			Expect(x1).To(Equal(1))
+			Expect(y1).To(Equal(2))
+			Expect(x2).To(Equal(5))
+			Expect(y2).To(Equal(4))
+			Expect(char).To(Equal("Q"))
 		})
 
-		It("upcases the command action and char", func() {
-			_, err := io.WriteString(inBuf, "l 1 3 a")
+		Context("if calling Rect on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.RectReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "R 1 2 5 4 Q")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Box instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "B 1 2 5 4 Q")
 			Expect(err).NotTo(HaveOccurred())
 
 			r.ProcessEditActions()
 
-			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
-			x, y, char := fakeImageEditor.SetArgsForCall(0)
-			Expect(x).To(Equal(1))
-			Expect(y).To(Equal(3))
-			Expect(char).To(Equal("A"))
+			Expect(fakeImageEditor.BoxCallCount()).To(Equal(1))
+			x1, y1, x2, y2, char := fakeImageEditor.BoxArgsForCall(0)
+			Expect(x1).To(Equal(1))
+			Expect(y1).To(Equal(2))
+			Expect(x2).To(Equal(5))
+			Expect(y2).To(Equal(4))
+			Expect(char).To(Equal("Q"))
 		})
 
-		Context("if any coordinate argument cannot be translated into an integer", func() {
-			It("prints the error", func() {
-				_, err := io.WriteString(inBuf, "L p 3 A")
+		Context("if calling Box on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.BoxReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "B 1 2 5 4 Q")
 				Expect(err).NotTo(HaveOccurred())
 
 				r.ProcessEditActions()
-				Expect(outBuf).To(gbytes.Say("could not parse non-integer 'p'"))
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Circle instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "O 4 5 3 K")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.CircleCallCount()).To(Equal(1))
+			x, y, radius, char := fakeImageEditor.CircleArgsForCall(0)
+			Expect(x).To(Equal(4))
+			Expect(y).To(Equal(5))
+			Expect(radius).To(Equal(3))
+			Expect(char).To(Equal("K"))
+		})
+
+		Context("if the editor rejects a circle", func() {
+			It("prints an error", func() {
+				fakeImageEditor.CircleReturns(errors.New("unknown colour 'RED!'"))
+				_, err := io.WriteString(inBuf, "O 4 5 3 red!")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("unknown colour 'RED!'"))
+			})
+		})
+
+		It("forwards FilledCircle instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "FO 4 5 3 K")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.FilledCircleCallCount()).To(Equal(1))
+			x, y, radius, char := fakeImageEditor.FilledCircleArgsForCall(0)
+			Expect(x).To(Equal(4))
+			Expect(y).To(Equal(5))
+			Expect(radius).To(Equal(3))
+			Expect(char).To(Equal("K"))
+		})
+
+		It("forwards Ellipse instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "E 4 5 3 2 K")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.EllipseCallCount()).To(Equal(1))
+			x, y, rx, ry, char := fakeImageEditor.EllipseArgsForCall(0)
+			Expect(x).To(Equal(4))
+			Expect(y).To(Equal(5))
+			Expect(rx).To(Equal(3))
+			Expect(ry).To(Equal(2))
+			Expect(char).To(Equal("K"))
+		})
+
+		It("forwards FilledEllipse instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "FE 4 5 3 2 K")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.FilledEllipseCallCount()).To(Equal(1))
+			x, y, rx, ry, char := fakeImageEditor.FilledEllipseArgsForCall(0)
+			Expect(x).To(Equal(4))
+			Expect(y).To(Equal(5))
+			Expect(rx).To(Equal(3))
+			Expect(ry).To(Equal(2))
+			Expect(char).To(Equal("K"))
+		})
+
+		It("forwards Fill instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "F 2 4 K")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.FillCallCount()).To(Equal(1))
+			x, y, char := fakeImageEditor.FillArgsForCall(0)
+			Expect(x).To(Equal(2))
+			Expect(y).To(Equal(4))
+			Expect(char).To(Equal("K"))
+		})
+
+		Context("if calling Fill on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.FillReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "F 2 4 K")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.FillCallCount()).To(Equal(1))
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Copy instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "Y 1 2 3 4")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.CopyCallCount()).To(Equal(1))
+			x1, y1, x2, y2 := fakeImageEditor.CopyArgsForCall(0)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 2, 3, 4}))
+		})
+
+		Context("if calling Copy on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.CopyReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "Y 1 2 3 4")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Paste instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "P 3 4")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.PasteCallCount()).To(Equal(1))
+			x, y := fakeImageEditor.PasteArgsForCall(0)
+			Expect(x).To(Equal(3))
+			Expect(y).To(Equal(4))
+			Expect(fakeImageEditor.PNGCallCount()).To(Equal(0))
+		})
+
+		Context("if calling Paste on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.PasteReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "P 3 4")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Move instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "M 1 2 3 4 -2 5")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.MoveCallCount()).To(Equal(1))
+			x1, y1, x2, y2, dx, dy := fakeImageEditor.MoveArgsForCall(0)
+			Expect([]int{x1, y1, x2, y2, dx, dy}).To(Equal([]int{1, 2, 3, 4, -2, 5}))
+		})
+
+		Context("if calling Move on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.MoveReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "M 1 2 3 4 -2 5")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Rotate instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "RO 270")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RotateCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.RotateArgsForCall(0)).To(Equal(270))
+		})
+
+		Context("if calling Rotate on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.RotateReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "RO 45")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards flip and transpose instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "FH\nFV\nT")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.FlipHorizontalCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.FlipVerticalCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.TransposeCallCount()).To(Equal(1))
+		})
+
+		Describe("resizing", func() {
+			BeforeEach(func() {
+				fakeImageEditor.SizeReturns(4, 3)
+			})
+
+			It("forwards Resize instructions to the editor, anchored top left by default", func() {
+				_, err := io.WriteString(inBuf, "Z 8 5")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.ResizeCallCount()).To(Equal(1))
+				cols, rows, dx, dy := fakeImageEditor.ResizeArgsForCall(0)
+				Expect([]int{cols, rows, dx, dy}).To(Equal([]int{8, 5, 0, 0}))
 			})
+
+			It("places the image by the given anchor", func() {
+				_, err := io.WriteString(inBuf, "Z 8 5 c\nZ 8 5 BR\nZ 2 2 B")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.ResizeCallCount()).To(Equal(3))
+				cols, rows, dx, dy := fakeImageEditor.ResizeArgsForCall(0)
+				Expect([]int{cols, rows, dx, dy}).To(Equal([]int{8, 5, 2, 1}))
+				cols, rows, dx, dy = fakeImageEditor.ResizeArgsForCall(1)
+				Expect([]int{cols, rows, dx, dy}).To(Equal([]int{8, 5, 4, 2}))
+				cols, rows, dx, dy = fakeImageEditor.ResizeArgsForCall(2)
+				Expect([]int{cols, rows, dx, dy}).To(Equal([]int{2, 2, -1, -1}))
+			})
+
+			It("forwards Scale instructions to the editor", func() {
+				_, err := io.WriteString(inBuf, "K 2\nK 10 7")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.ScaleCallCount()).To(Equal(2))
+				cols, rows := fakeImageEditor.ScaleArgsForCall(0)
+				Expect([]int{cols, rows}).To(Equal([]int{8, 6}))
+				cols, rows = fakeImageEditor.ScaleArgsForCall(1)
+				Expect([]int{cols, rows}).To(Equal([]int{10, 7}))
+			})
+
+			Context("if the anchor is not recognised", func() {
+				It("prints the anchors it accepts", func() {
+					_, err := io.WriteString(inBuf, "Z 8 5 middle")
+					Expect(err).NotTo(HaveOccurred())
+
+					r.ProcessEditActions()
+					Expect(fakeImageEditor.ResizeCallCount()).To(Equal(0))
+					Expect(outBuf).To(gbytes.Say("unknown anchor 'middle', use TL, T, TR, L, C, R, BL, B or BR"))
+				})
+			})
+
+			Context("if the new size is out of range", func() {
+				It("prints an error", func() {
+					_, err := io.WriteString(inBuf, "Z 65537 5\nK 20000\nK 0 5")
+					Expect(err).NotTo(HaveOccurred())
+
+					r.ProcessEditActions()
+					Expect(fakeImageEditor.ResizeCallCount()).To(Equal(0))
+					Expect(fakeImageEditor.ScaleCallCount()).To(Equal(0))
+					Expect(outBuf).To(gbytes.Say("image axis out of range"))
+					Expect(outBuf).To(gbytes.Say("image axis out of range"))
+					Expect(outBuf).To(gbytes.Say("image axis out of range"))
+				})
+			})
+		})
+
+		It("forwards Crop instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "X 1 2 3 4")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.CropCallCount()).To(Equal(1))
+			x1, y1, x2, y2 := fakeImageEditor.CropArgsForCall(0)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 2, 3, 4}))
+		})
+
+		It("forwards CropToContent instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "x auto")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.CropToContentCallCount()).To(Equal(1))
+		})
+
+		Context("if calling CropToContent on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.CropToContentReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "X AUTO")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		Context("if X is given a word other than AUTO", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "X ALL\nX 1 2")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.CropToContentCallCount()).To(Equal(0))
+				Expect(outBuf).To(gbytes.Say("expected AUTO, got 'ALL'"))
+				Expect(outBuf).To(gbytes.Say("X expects 4 coordinates, or AUTO, got 2"))
+			})
+		})
+
+		It("forwards layer instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "LN notes\nLS base\nLH notes\nLV notes\nLO notes 1\nLM notes")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.AddLayerArgsForCall(0)).To(Equal("notes"))
+			Expect(fakeImageEditor.SelectLayerArgsForCall(0)).To(Equal("base"))
+			name, hidden := fakeImageEditor.HideLayerArgsForCall(0)
+			Expect(name).To(Equal("notes"))
+			Expect(hidden).To(BeTrue())
+			name, hidden = fakeImageEditor.HideLayerArgsForCall(1)
+			Expect(name).To(Equal("notes"))
+			Expect(hidden).To(BeFalse())
+			name, position := fakeImageEditor.MoveLayerArgsForCall(0)
+			Expect(name).To(Equal("notes"))
+			Expect(position).To(Equal(1))
+			Expect(fakeImageEditor.MergeLayerArgsForCall(0)).To(Equal("notes"))
+		})
+
+		Context("if a layer instruction fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.SelectLayerReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "LS nope")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Show instructions to the editor", func() {
+			fakeImageEditor.RenderStub = func(w io.Writer) error {
+				_, err := io.WriteString(w, "OA\nOO\n")
+				return err
+			}
+			_, err := io.WriteString(inBuf, "S")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RenderCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.RenderArgsForCall(0)).To(Equal(outBuf))
+			Expect(outBuf.Contents()).To(Equal([]byte("OA\nOO\n\n")))
+		})
+
+		It("forwards a region to show, and whether to draw rulers, to the editor", func() {
+			fakeImageEditor.SizeReturns(5, 4)
+			_, err := io.WriteString(inBuf, "S 2 3 4 1\nS 1 1 2 2 rulers\nS RULERS")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RenderCallCount()).To(Equal(0))
+			Expect(fakeImageEditor.RenderRegionCallCount()).To(Equal(3))
+
+			_, x1, y1, x2, y2, rulers, colours := fakeImageEditor.RenderRegionArgsForCall(0)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{2, 3, 4, 1}))
+			Expect(rulers).To(BeFalse())
+			Expect(colours).To(Equal(0))
+
+			_, x1, y1, x2, y2, rulers, _ = fakeImageEditor.RenderRegionArgsForCall(1)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 1, 2, 2}))
+			Expect(rulers).To(BeTrue())
+
+			_, x1, y1, x2, y2, rulers, _ = fakeImageEditor.RenderRegionArgsForCall(2)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 1, 5, 4}))
+			Expect(rulers).To(BeTrue())
+		})
+
+		It("shows the image in colour when asked to", func() {
+			fakeImageEditor.SizeReturns(5, 4)
+			_, err := io.WriteString(inBuf, "S COLOR\nS rulers color\nS 1 1 2 2 COLOR")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RenderRegionCallCount()).To(Equal(3))
+
+			_, x1, y1, x2, y2, rulers, colours := fakeImageEditor.RenderRegionArgsForCall(0)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 1, 5, 4}))
+			Expect(rulers).To(BeFalse())
+			Expect(colours).To(Equal(256))
+
+			_, _, _, _, _, rulers, colours = fakeImageEditor.RenderRegionArgsForCall(1)
+			Expect(rulers).To(BeTrue())
+			Expect(colours).To(Equal(256))
+
+			_, x1, y1, x2, y2, _, colours = fakeImageEditor.RenderRegionArgsForCall(2)
+			Expect([]int{x1, y1, x2, y2}).To(Equal([]int{1, 1, 2, 2}))
+			Expect(colours).To(Equal(256))
+		})
+
+		It("shows the image in colour by default once colour is set", func() {
+			fakeImageEditor.SizeReturns(5, 4)
+			r.SetColour(true)
+			r.SetColours(1 << 24)
+			_, err := io.WriteString(inBuf, "S")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RenderCallCount()).To(Equal(0))
+			_, _, _, _, _, _, colours := fakeImageEditor.RenderRegionArgsForCall(0)
+			Expect(colours).To(Equal(1 << 24))
+		})
+
+		Context("if the region to show is off the image", func() {
+			It("prints an error", func() {
+				fakeImageEditor.RenderRegionReturns(errors.New("given coordinate is beyond image grid"))
+				_, err := io.WriteString(inBuf, "S 1 1 9 9")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("given coordinate is beyond image grid"))
+			})
+		})
+
+		Context("if S is given the wrong arguments", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "S 1 1 2\nS LINES\nS COLOR COLOR")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("S expects an optional COLOR and an optional RULERS, or 4 coordinates, an optional COLOR and an optional RULERS, got 3"))
+				Expect(outBuf).To(gbytes.Say("expected COLOR or RULERS, got 'LINES'"))
+				Expect(outBuf).To(gbytes.Say("COLOR given more than once"))
+				Expect(fakeImageEditor.RenderRegionCallCount()).To(Equal(0))
+			})
+		})
+
+		It("forwards Clear instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "C")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+		})
+
+		It("forwards Undo instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "U")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.UndoCallCount()).To(Equal(1))
+		})
+
+		Context("if calling Undo on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.UndoReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "U")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		It("forwards Redo instructions to the editor", func() {
+			_, err := io.WriteString(inBuf, "R")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.RedoCallCount()).To(Equal(1))
+		})
+
+		Context("if calling Redo on the editor fails", func() {
+			BeforeEach(func() {
+				fakeImageEditor.RedoReturns(errors.New("EXPLODE"))
+			})
+
+			It("forwards the error", func() {
+				_, err := io.WriteString(inBuf, "R")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		Describe("files", func() {
+			var dir string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "runner")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			It("forwards Save instructions to the editor, writing to the given file", func() {
+				fakeImageEditor.SaveStub = func(w io.Writer) error {
+					_, err := io.WriteString(w, "saved")
+					return err
+				}
+				path := filepath.Join(dir, "Image.bmp")
+				_, err := io.WriteString(inBuf, "W "+path)
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.SaveCallCount()).To(Equal(1))
+				Expect(ioutil.ReadFile(path)).To(Equal([]byte("saved")))
+			})
+
+			It("forwards Load instructions to the editor, reading from the given file", func() {
+				path := filepath.Join(dir, "Image.bmp")
+				Expect(ioutil.WriteFile(path, []byte("saved"), 0644)).To(Succeed())
+				var loaded []byte
+				fakeImageEditor.LoadStub = func(r io.Reader) error {
+					var err error
+					loaded, err = ioutil.ReadAll(r)
+					return err
+				}
+				_, err := io.WriteString(inBuf, "O "+path)
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.LoadCallCount()).To(Equal(1))
+				Expect(fakeImageEditor.CircleCallCount()).To(Equal(0))
+				Expect(loaded).To(Equal([]byte("saved")))
+			})
+
+			It("forwards PNG instructions to the editor, writing to the given file", func() {
+				fakeImageEditor.PNGStub = func(w io.Writer, scale int) error {
+					_, err := io.WriteString(w, "png")
+					return err
+				}
+				path := filepath.Join(dir, "Image.png")
+				_, err := io.WriteString(inBuf, "P "+path+" 4")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.PNGCallCount()).To(Equal(1))
+				_, scale := fakeImageEditor.PNGArgsForCall(0)
+				Expect(scale).To(Equal(4))
+				Expect(ioutil.ReadFile(path)).To(Equal([]byte("png")))
+			})
+
+			It("exports PNGs at scale 1 by default", func() {
+				_, err := io.WriteString(inBuf, "P "+filepath.Join(dir, "Image.png"))
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+
+				Expect(fakeImageEditor.PNGCallCount()).To(Equal(1))
+				_, scale := fakeImageEditor.PNGArgsForCall(0)
+				Expect(scale).To(Equal(1))
+			})
+
+			Context("if the file cannot be opened", func() {
+				It("prints the error", func() {
+					_, err := io.WriteString(inBuf, "O "+filepath.Join(dir, "missing.bmp"))
+					Expect(err).NotTo(HaveOccurred())
+
+					r.ProcessEditActions()
+
+					Expect(fakeImageEditor.LoadCallCount()).To(Equal(0))
+					Expect(outBuf).To(gbytes.Say("no such file or directory"))
+				})
+			})
+
+			Context("if calling Load on the editor fails", func() {
+				BeforeEach(func() {
+					fakeImageEditor.LoadReturns(errors.New("EXPLODE"))
+				})
+
+				It("forwards the error", func() {
+					path := filepath.Join(dir, "Image.bmp")
+					Expect(ioutil.WriteFile(path, []byte("saved"), 0644)).To(Succeed())
+					_, err := io.WriteString(inBuf, "O "+path)
+					Expect(err).NotTo(HaveOccurred())
+
+					r.ProcessEditActions()
+					Expect(outBuf).To(gbytes.Say("EXPLODE"))
+				})
+			})
+
+			Context("if the loaded image is outside the size limits", func() {
+				It("fails, and keeps the image it had", func() {
+					path := filepath.Join(dir, "Image.bmp")
+					Expect(ioutil.WriteFile(path, []byte("saved"), 0644)).To(Succeed())
+					loaded := new(runnerfakes.FakeImageEditor)
+					loaded.SizeReturns(70000, 1)
+					r.SetEditorFactory(func() runner.ImageEditor { return loaded })
+					fakeImageEditor.SizeReturns(3, 2)
+					_, err := io.WriteString(inBuf, "O "+path+"\nDOCS")
+					Expect(err).NotTo(HaveOccurred())
+
+					r.ProcessEditActions()
+					Expect(loaded.LoadCallCount()).To(Equal(1))
+					Expect(outBuf).To(gbytes.Say("image axis out of range"))
+					Expect(outBuf).To(gbytes.Say("main 3x2"))
+				})
+			})
+		})
+
+		It("upcases the command action and char", func() {
+			_, err := io.WriteString(inBuf, "l 1 3 a")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+			x, y, char := fakeImageEditor.SetArgsForCall(0)
+			Expect(x).To(Equal(1))
+			Expect(y).To(Equal(3))
+			Expect(char).To(Equal("A"))
+		})
+
+		It("creates a new image when given another image command", func() {
+			_, err := io.WriteString(inBuf, "I 3 4")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+
+			Expect(fakeImageEditor.CreateImageCallCount()).To(Equal(1))
+			cols, rows := fakeImageEditor.CreateImageArgsForCall(0)
+			Expect(cols).To(Equal(3))
+			Expect(rows).To(Equal(4))
+		})
+
+		It("splits commands on any run of spaces and tabs", func() {
+			_, err := io.WriteString(inBuf, "  L\t1   3 \t a\t\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.ProcessEditActions()).To(Succeed())
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+			x, y, char := fakeImageEditor.SetArgsForCall(0)
+			Expect(x).To(Equal(1))
+			Expect(y).To(Equal(3))
+			Expect(char).To(Equal("A"))
+		})
+
+		It("skips comments and keeps going past blank lines", func() {
+			_, err := io.WriteString(inBuf, "# start\nC\n\n\nL 1 3 A # a pixel\n# end\nC\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.ProcessEditActions()).To(Succeed())
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(2))
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+			Expect(outBuf.Contents()).To(BeEmpty())
+		})
+
+		It("keeps a # that does not start a word", func() {
+			_, err := io.WriteString(inBuf, "O no#such.txt\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+			Expect(outBuf).To(gbytes.Say("no#such.txt"))
+		})
+
+		It("counts skipped lines when reporting errors", func() {
+			r.SetSource("script")
+			_, err := io.WriteString(inBuf, "# header\n\nL 1\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+			Expect(outBuf).To(gbytes.Say("script:3: L expects 2 coordinates and a colour, got 1"))
+		})
+
+		Context("if the action is not recognised", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "Q 1 3 A")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("invalid action"))
+			})
+		})
+
+		Context("if an action is given too few arguments", func() {
+			It("prints what the action expects", func() {
+				_, err := io.WriteString(inBuf, "V 2 A")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.SetMultiYCallCount()).To(Equal(0))
+				Expect(outBuf).To(gbytes.Say("V expects 3 coordinates and a colour, got 2"))
+			})
+		})
+
+		Context("if an action is given too many arguments that are not numbers", func() {
+			It("counts every argument given", func() {
+				_, err := io.WriteString(inBuf, "L A B C D")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("L expects 2 coordinates and a colour, got 4"))
+			})
+		})
+
+		Context("if a number is far beyond any image", func() {
+			It("prints an error", func() {
+				_, err := io.WriteString(inBuf, "O 1 1 100000000 A")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.CircleCallCount()).To(Equal(0))
+				Expect(outBuf).To(gbytes.Say("number out of range: -131072 <= n <= 131072, got 100000000"))
+			})
+
+			It("reports it against the form of the action it was given in", func() {
+				_, err := io.WriteString(inBuf, "P out.png 100000000")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("number out of range: .*, got 100000000"))
+			})
+
+			It("fails as a range error rather than a parse error", func() {
+				err := r.Execute("D 1 1 100000000 1 A")
+				Expect(errors.Is(err, runner.ErrArgumentRange)).To(BeTrue())
+
+				var parseErr *runner.ParseError
+				Expect(errors.As(err, &parseErr)).To(BeFalse())
+			})
+		})
+
+		Context("if an action is given too many arguments", func() {
+			It("prints what the action expects", func() {
+				_, err := io.WriteString(inBuf, "C 1")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(0))
+				Expect(outBuf).To(gbytes.Say("C expects no arguments, got 1"))
+			})
+		})
+
+		Context("if an overloaded action fits none of its forms", func() {
+			It("prints every form the action expects", func() {
+				_, err := io.WriteString(inBuf, "R 1 2")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("R expects 4 coordinates and a colour, or no arguments, got 2"))
+			})
+		})
+
+		Context("if an optional argument is given", func() {
+			It("describes it as optional", func() {
+				_, err := io.WriteString(inBuf, "P")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("P expects 2 coordinates, or a path and an optional scale, got 0"))
+			})
+		})
+
+		Context("if the runner is strict", func() {
+			BeforeEach(func() {
+				r.SetStrict(true)
+				fakeImageEditor.SetReturns(errors.New("EXPLODE"))
+			})
+
+			It("stops at the first failed command and returns its error", func() {
+				_, err := io.WriteString(inBuf, "C\nL 1 3 A\nC\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessEditActions()).To(MatchError("EXPLODE"))
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+				Expect(outBuf.Contents()).To(BeEmpty())
+			})
+
+			It("returns parse errors as a ParseError", func() {
+				_, err := io.WriteString(inBuf, "L 1\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = r.ProcessEditActions()
+				var parseErr *runner.ParseError
+				Expect(errors.As(err, &parseErr)).To(BeTrue())
+			})
+
+			It("returns file errors as a FileError", func() {
+				_, err := io.WriteString(inBuf, "O /does/not/exist.bmp\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = r.ProcessEditActions()
+				var fileErr *runner.FileError
+				Expect(errors.As(err, &fileErr)).To(BeTrue())
+			})
+		})
+
+		Context("if the runner is not strict", func() {
+			It("prints errors and carries on", func() {
+				fakeImageEditor.SetReturns(errors.New("EXPLODE"))
+				_, err := io.WriteString(inBuf, "L 1 3 A\nC\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessEditActions()).To(Succeed())
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+				Expect(outBuf).To(gbytes.Say("EXPLODE"))
+			})
+		})
+
+		Context("if the runner has been given a source name", func() {
+			BeforeEach(func() {
+				r.SetSource("shapes.bmp")
+				fakeImageEditor.SetMultiXReturns(errors.New("EXPLODE"))
+			})
+
+			It("prefixes errors with the source name and line number", func() {
+				_, err := io.WriteString(inBuf, "L 1 3 A\nC\nH 3 5 2 Z\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("shapes.bmp:3: EXPLODE"))
+			})
+
+			It("counts the image command as the first line", func() {
+				_, err := io.WriteString(inBuf, "I 5 5\nH 3 5 2 Z\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(Succeed())
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("shapes.bmp:2: EXPLODE"))
+			})
+
+			It("prefixes image command errors too", func() {
+				_, err := io.WriteString(inBuf, "I 0 5\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessImageSize()).To(MatchError("shapes.bmp:1: image axis out of range: 1 <= M,N <= 65536"))
+			})
+		})
+
+		Context("if any coordinate argument cannot be translated into an integer", func() {
+			It("prints the error", func() {
+				_, err := io.WriteString(inBuf, "L p 3 A")
+				Expect(err).NotTo(HaveOccurred())
+
+				r.ProcessEditActions()
+				Expect(outBuf).To(gbytes.Say("could not parse non-integer 'p'"))
+			})
+		})
+	})
+
+	Describe("scripting", func() {
+		run := func(script string) {
+			_, err := io.WriteString(inBuf, script)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.ProcessEditActions()).To(Succeed())
+		}
+
+		sets := func() [][]interface{} {
+			calls := [][]interface{}{}
+			for i := 0; i < fakeImageEditor.SetCallCount(); i++ {
+				x, y, char := fakeImageEditor.SetArgsForCall(i)
+				calls = append(calls, []interface{}{x, y, char})
+			}
+			return calls
+		}
+
+		It("substitutes variables set with SET", func() {
+			run("SET x 2\nSET c b\nL $x 3 $c\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{2, 3, "B"}}))
+		})
+
+		It("works out arithmetic in coordinates", func() {
+			run("SET n 4\nL $n+1 (2+1)*2-$n/2 A\nL 7%4 -1+2 A\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{5, 4, "A"}, {3, 1, "A"}}))
+		})
+
+		It("works out sums when a variable is set", func() {
+			run("SET n 1\nSET n $n+1\nSET n $n*3\nL $n 1 A\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{6, 1, "A"}}))
+		})
+
+		It("substitutes variables inside paths", func() {
+			run("SET name frame\nO $name-1.bmp\n")
+
+			Expect(outBuf).To(gbytes.Say("frame-1.bmp"))
+		})
+
+		It("repeats a block k times", func() {
+			run("REPEAT 3\nC\nEND\n")
+
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(3))
+		})
+
+		It("counts through a REPEAT in the given variable", func() {
+			run("REPEAT 3 i\nL $i $i*2 A\nEND\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{1, 2, "A"}, {2, 4, "A"}, {3, 6, "A"}}))
+		})
+
+		It("runs nested blocks", func() {
+			run("repeat 2 y\nREPEAT 2 x\nL $x $y A\nEND\nend\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{1, 1, "A"}, {2, 1, "A"}, {1, 2, "A"}, {2, 2, "A"}}))
+		})
+
+		It("skips a block repeated no times", func() {
+			run("REPEAT 0\nC\nEND\n")
+
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(0))
+		})
+
+		It("calls macros defined with DEF like commands", func() {
+			run("DEF dot x y c\nL $x $y $c\nL $x+1 $y $c\nEND\ndot 2 3 z\nDOT 5 1 A\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{2, 3, "Z"}, {3, 3, "Z"}, {5, 1, "A"}, {6, 1, "A"}}))
+		})
+
+		It("puts back variables a macro's parameters hide", func() {
+			run("SET x 7\nDEF dot x\nL $x 1 A\nEND\ndot 2\nL $x 1 A\n")
+
+			Expect(sets()).To(Equal([][]interface{}{{2, 1, "A"}, {7, 1, "A"}}))
+		})
+
+		It("reports errors against the line within the block", func() {
+			r.SetSource("grid.bmp")
+			fakeImageEditor.SetReturnsOnCall(1, errors.New("EXPLODE"))
+			run("REPEAT 2\nC\nL 1 1 A\nEND\nC\n")
+
+			Expect(outBuf).To(gbytes.Say("grid.bmp:3: EXPLODE"))
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(3))
+		})
+
+		Context("if a variable is not set", func() {
+			It("prints an error", func() {
+				run("L $x 1 A\n")
+
+				Expect(outBuf).To(gbytes.Say("undefined variable '\\$x'"))
+				Expect(fakeImageEditor.SetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if a sum divides by zero", func() {
+			It("prints an error", func() {
+				run("L 4/0 1 A\n")
+
+				Expect(outBuf).To(gbytes.Say("division by zero in '4/0'"))
+			})
+		})
+
+		Context("if a sum overflows", func() {
+			It("prints an error rather than drawing where it wraps to", func() {
+				run("L 1073741824*1073741824*16+2 1 A\n")
+
+				Expect(outBuf).To(gbytes.Say(`integer overflow in '1073741824\*1073741824\*16\+2'`))
+				Expect(fakeImageEditor.SetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if a macro is given the wrong number of arguments", func() {
+			It("prints what the macro expects", func() {
+				run("DEF dot x y\nEND\ndot 1\n")
+
+				Expect(outBuf).To(gbytes.Say("DOT expects 2 arguments, got 1"))
+			})
+		})
+
+		Context("if a macro is named after a built-in command", func() {
+			It("prints an error", func() {
+				run("DEF l x\nEND\n")
+
+				Expect(outBuf).To(gbytes.Say("cannot redefine built-in command 'L'"))
+			})
+		})
+
+		Context("if a macro calls itself", func() {
+			It("stops and prints an error", func() {
+				run("DEF loop\nloop\nEND\nloop\n")
+
+				Expect(outBuf).To(gbytes.Say("LOOP: macro calls nested too deeply"))
+			})
+		})
+
+		Context("if the commands run past the budget", func() {
+			It("stops and prints an error", func() {
+				r.SetBudget(5)
+				run("REPEAT 1000000000\nREPEAT 1000000000\nEND\nEND\nC\n")
+
+				Expect(outBuf).To(gbytes.Say("command budget used up, run fewer commands"))
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if a block has no END", func() {
+			It("prints an error against the block's first line", func() {
+				r.SetSource("grid.bmp")
+				run("C\nREPEAT 2\nC\n")
+
+				Expect(outBuf).To(gbytes.Say("grid.bmp:2: REPEAT without END"))
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("if END has no block", func() {
+			It("prints an error", func() {
+				run("END\n")
+
+				Expect(outBuf).To(gbytes.Say("END without REPEAT or DEF"))
+			})
+		})
+
+		Context("if the runner is strict", func() {
+			It("stops at the first failed command within a block", func() {
+				r.SetStrict(true)
+				r.SetSource("grid.bmp")
+				fakeImageEditor.SetReturns(errors.New("EXPLODE"))
+				_, err := io.WriteString(inBuf, "REPEAT 3\nL 1 1 A\nEND\nC\n")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(r.ProcessEditActions()).To(MatchError("grid.bmp:2: EXPLODE"))
+				Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+				Expect(fakeImageEditor.ClearCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("documents", func() {
+		var created []*runnerfakes.FakeImageEditor
+
+		BeforeEach(func() {
+			created = nil
+			r.SetEditorFactory(func() runner.ImageEditor {
+				fake := new(runnerfakes.FakeImageEditor)
+				fake.CreateImageStub = func(cols, rows int) {
+					fake.SizeReturns(cols, rows)
+				}
+				created = append(created, fake)
+				return fake
+			})
+		})
+
+		run := func(script string) {
+			_, err := io.WriteString(inBuf, script)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.ProcessEditActions()).To(Succeed())
+		}
+
+		It("creates a named document and draws on it", func() {
+			run("I icon 3 4\nL 1 2 A\n")
+
+			Expect(created).To(HaveLen(1))
+			cols, rows := created[0].CreateImageArgsForCall(0)
+			Expect(cols).To(Equal(3))
+			Expect(rows).To(Equal(4))
+			Expect(created[0].SetCallCount()).To(Equal(1))
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(0))
+		})
+
+		It("replaces a document created again", func() {
+			run("I icon 3 4\nI icon 5 5\n")
+
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].CreateImageCallCount()).To(Equal(2))
+		})
+
+		It("switches between documents", func() {
+			run("I icon 3 4\nUSE main\nL 1 2 A\nuse icon\nC\n")
+
+			Expect(fakeImageEditor.SetCallCount()).To(Equal(1))
+			Expect(created[0].ClearCallCount()).To(Equal(1))
+		})
+
+		It("reports the size of the document in use", func() {
+			run("I icon 3 4\n")
+
+			cols, rows := r.Size()
+			Expect(cols).To(Equal(3))
+			Expect(rows).To(Equal(4))
+		})
+
+		It("lists the documents, marking the one in use", func() {
+			fakeImageEditor.SizeReturns(5, 5)
+			run("I icon 3 4\nI badge 2 2\nUSE icon\nDOCS\n")
+
+			Expect(outBuf).To(gbytes.Say("  badge 2x2\n\\* icon 3x4\n  main 5x5\n"))
+		})
+
+		It("closes a document", func() {
+			run("I icon 3 4\nUSE main\nCLOSE icon\nUSE icon\n")
+
+			Expect(outBuf).To(gbytes.Say("no document named 'icon'"))
+		})
+
+		It("copies another document into this one", func() {
+			picture := new(int)
+			fakeImageEditor.PictureReturns(picture)
+			run("I sheet 6 2\nCOPY main 3 2\n")
+
+			Expect(created[0].PastePictureCallCount()).To(Equal(1))
+			pasted, x, y := created[0].PastePictureArgsForCall(0)
+			Expect(pasted).To(BeIdenticalTo(picture))
+			Expect(x).To(Equal(3))
+			Expect(y).To(Equal(2))
+		})
+
+		Context("if the document does not exist", func() {
+			It("prints an error", func() {
+				run("USE icon\nCLOSE icon\nCOPY icon 1 1\n")
+
+				Expect(outBuf).To(gbytes.Say("no document named 'icon'"))
+				Expect(outBuf).To(gbytes.Say("no document named 'icon'"))
+				Expect(outBuf).To(gbytes.Say("no document named 'icon'"))
+			})
+		})
+
+		Context("if the document is in use", func() {
+			It("will not close it", func() {
+				run("CLOSE main\n")
+
+				Expect(outBuf).To(gbytes.Say("cannot close 'main' while it is in use"))
+			})
+		})
+
+		Context("if the size is out of range", func() {
+			It("prints an error", func() {
+				run("I icon 0 4\n")
+
+				Expect(created).To(BeEmpty())
+				Expect(outBuf).To(gbytes.Say("image axis out of range"))
+			})
+		})
+
+		Context("if the runner has no editor factory", func() {
+			It("cannot create documents", func() {
+				r.SetEditorFactory(nil)
+				run("I icon 3 4\n")
+
+				Expect(outBuf).To(gbytes.Say("new documents cannot be created here"))
+			})
+		})
+	})
+
+	Describe("DisableFiles", func() {
+		It("refuses commands that read or write files", func() {
+			r.DisableFiles()
+			_, err := io.WriteString(inBuf, "W out.bmp\nO in.bmp\nP out.png\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			r.ProcessEditActions()
+			Expect(outBuf).To(gbytes.Say("file access is disabled"))
+			Expect(outBuf).To(gbytes.Say("file access is disabled"))
+			Expect(outBuf).To(gbytes.Say("file access is disabled"))
+			Expect(fakeImageEditor.SaveCallCount()).To(Equal(0))
+			Expect(fakeImageEditor.LoadCallCount()).To(Equal(0))
+			Expect(fakeImageEditor.PNGCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Flush", func() {
+		It("drops a block left open, reporting where it started", func() {
+			r.SetSource("shell")
+			Expect(r.Execute("C")).To(Succeed())
+			Expect(r.Execute("REPEAT 2")).To(Succeed())
+			Expect(r.Execute("C")).To(Succeed())
+			Expect(r.Pending()).To(BeTrue())
+
+			Expect(r.Flush()).To(MatchError("shell:2: REPEAT without END"))
+			Expect(r.Pending()).To(BeFalse())
+			Expect(fakeImageEditor.ClearCallCount()).To(Equal(1))
+		})
+
+		It("does nothing when no block is open", func() {
+			Expect(r.Flush()).To(Succeed())
+		})
+	})
+
+	Describe("SetInput", func() {
+		It("reads from the new input, counting lines afresh and keeping state", func() {
+			r.SetSource("first.bmp")
+			_, err := io.WriteString(inBuf, "SET c A\nC\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.ProcessEditActions()).To(Succeed())
+
+			next := gbytes.NewBuffer()
+			_, err = io.WriteString(next, "L 1 1 $c\nL 1\n")
+			Expect(err).NotTo(HaveOccurred())
+			r.SetInput(bufio.NewScanner(next))
+			r.SetSource("second.bmp")
+			Expect(r.ProcessEditActions()).To(Succeed())
+
+			_, _, char := fakeImageEditor.SetArgsForCall(0)
+			Expect(char).To(Equal("A"))
+			Expect(outBuf).To(gbytes.Say("second.bmp:2: L expects"))
 		})
 	})
 })
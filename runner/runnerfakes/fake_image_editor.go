@@ -2,181 +2,2272 @@
 package runnerfakes
 
 import (
+	"io"
 	"sync"
 
 	"github.com/mo-work/go-technical-test-for-claudia/runner"
 )
 
 type FakeImageEditor struct {
+	AddLayerStub        func(string) error
+	addLayerMutex       sync.RWMutex
+	addLayerArgsForCall []struct {
+		arg1 string
+	}
+	addLayerReturns struct {
+		result1 error
+	}
+	addLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	BoxStub        func(int, int, int, int, string) error
+	boxMutex       sync.RWMutex
+	boxArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}
+	boxReturns struct {
+		result1 error
+	}
+	boxReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CircleStub        func(int, int, int, string) error
+	circleMutex       sync.RWMutex
+	circleArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}
+	circleReturns struct {
+		result1 error
+	}
+	circleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ClearStub        func()
 	clearMutex       sync.RWMutex
 	clearArgsForCall []struct {
 	}
-	CreateImageStub        func(int, int)
-	createImageMutex       sync.RWMutex
-	createImageArgsForCall []struct {
+	CopyStub        func(int, int, int, int) error
+	copyMutex       sync.RWMutex
+	copyArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+	}
+	copyReturns struct {
+		result1 error
+	}
+	copyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateImageStub        func(int, int)
+	createImageMutex       sync.RWMutex
+	createImageArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	CropStub        func(int, int, int, int) error
+	cropMutex       sync.RWMutex
+	cropArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+	}
+	cropReturns struct {
+		result1 error
+	}
+	cropReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CropToContentStub        func() error
+	cropToContentMutex       sync.RWMutex
+	cropToContentArgsForCall []struct {
+	}
+	cropToContentReturns struct {
+		result1 error
+	}
+	cropToContentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EllipseStub        func(int, int, int, int, string) error
+	ellipseMutex       sync.RWMutex
+	ellipseArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}
+	ellipseReturns struct {
+		result1 error
+	}
+	ellipseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FillStub        func(int, int, string) error
+	fillMutex       sync.RWMutex
+	fillArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 string
+	}
+	fillReturns struct {
+		result1 error
+	}
+	fillReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FilledCircleStub        func(int, int, int, string) error
+	filledCircleMutex       sync.RWMutex
+	filledCircleArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}
+	filledCircleReturns struct {
+		result1 error
+	}
+	filledCircleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FilledEllipseStub        func(int, int, int, int, string) error
+	filledEllipseMutex       sync.RWMutex
+	filledEllipseArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}
+	filledEllipseReturns struct {
+		result1 error
+	}
+	filledEllipseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FlipHorizontalStub        func()
+	flipHorizontalMutex       sync.RWMutex
+	flipHorizontalArgsForCall []struct {
+	}
+	FlipVerticalStub        func()
+	flipVerticalMutex       sync.RWMutex
+	flipVerticalArgsForCall []struct {
+	}
+	HideLayerStub        func(string, bool) error
+	hideLayerMutex       sync.RWMutex
+	hideLayerArgsForCall []struct {
+		arg1 string
+		arg2 bool
+	}
+	hideLayerReturns struct {
+		result1 error
+	}
+	hideLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LineStub        func(int, int, int, int, string) error
+	lineMutex       sync.RWMutex
+	lineArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}
+	lineReturns struct {
+		result1 error
+	}
+	lineReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadStub        func(io.Reader) error
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+		arg1 io.Reader
+	}
+	loadReturns struct {
+		result1 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MergeLayerStub        func(string) error
+	mergeLayerMutex       sync.RWMutex
+	mergeLayerArgsForCall []struct {
+		arg1 string
+	}
+	mergeLayerReturns struct {
+		result1 error
+	}
+	mergeLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MoveStub        func(int, int, int, int, int, int) error
+	moveMutex       sync.RWMutex
+	moveArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 int
+		arg6 int
+	}
+	moveReturns struct {
+		result1 error
+	}
+	moveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MoveLayerStub        func(string, int) error
+	moveLayerMutex       sync.RWMutex
+	moveLayerArgsForCall []struct {
+		arg1 string
+		arg2 int
+	}
+	moveLayerReturns struct {
+		result1 error
+	}
+	moveLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PNGStub        func(io.Writer, int) error
+	pNGMutex       sync.RWMutex
+	pNGArgsForCall []struct {
+		arg1 io.Writer
+		arg2 int
+	}
+	pNGReturns struct {
+		result1 error
+	}
+	pNGReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PasteStub        func(int, int) error
+	pasteMutex       sync.RWMutex
+	pasteArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	pasteReturns struct {
+		result1 error
+	}
+	pasteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PastePictureStub        func(interface{}, int, int) error
+	pastePictureMutex       sync.RWMutex
+	pastePictureArgsForCall []struct {
+		arg1 interface{}
+		arg2 int
+		arg3 int
+	}
+	pastePictureReturns struct {
+		result1 error
+	}
+	pastePictureReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PictureStub        func() interface{}
+	pictureMutex       sync.RWMutex
+	pictureArgsForCall []struct {
+	}
+	pictureReturns struct {
+		result1 interface{}
+	}
+	pictureReturnsOnCall map[int]struct {
+		result1 interface{}
+	}
+	RectStub        func(int, int, int, int, string) error
+	rectMutex       sync.RWMutex
+	rectArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}
+	rectReturns struct {
+		result1 error
+	}
+	rectReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RedoStub        func() error
+	redoMutex       sync.RWMutex
+	redoArgsForCall []struct {
+	}
+	redoReturns struct {
+		result1 error
+	}
+	redoReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RenderStub        func(io.Writer) error
+	renderMutex       sync.RWMutex
+	renderArgsForCall []struct {
+		arg1 io.Writer
+	}
+	renderReturns struct {
+		result1 error
+	}
+	renderReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RenderRegionStub        func(io.Writer, int, int, int, int, bool, int) error
+	renderRegionMutex       sync.RWMutex
+	renderRegionArgsForCall []struct {
+		arg1 io.Writer
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 int
+		arg6 bool
+		arg7 int
+	}
+	renderRegionReturns struct {
+		result1 error
+	}
+	renderRegionReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ResizeStub        func(int, int, int, int)
+	resizeMutex       sync.RWMutex
+	resizeArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+	}
+	RotateStub        func(int) error
+	rotateMutex       sync.RWMutex
+	rotateArgsForCall []struct {
+		arg1 int
+	}
+	rotateReturns struct {
+		result1 error
+	}
+	rotateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveStub        func(io.Writer) error
+	saveMutex       sync.RWMutex
+	saveArgsForCall []struct {
+		arg1 io.Writer
+	}
+	saveReturns struct {
+		result1 error
+	}
+	saveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ScaleStub        func(int, int)
+	scaleMutex       sync.RWMutex
+	scaleArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	SelectLayerStub        func(string) error
+	selectLayerMutex       sync.RWMutex
+	selectLayerArgsForCall []struct {
+		arg1 string
+	}
+	selectLayerReturns struct {
+		result1 error
+	}
+	selectLayerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetStub        func(int, int, string) error
+	setMutex       sync.RWMutex
+	setArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 string
+	}
+	setReturns struct {
+		result1 error
+	}
+	setReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetMultiXStub        func(int, int, int, string) error
+	setMultiXMutex       sync.RWMutex
+	setMultiXArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}
+	setMultiXReturns struct {
+		result1 error
+	}
+	setMultiXReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetMultiYStub        func(int, int, int, string) error
+	setMultiYMutex       sync.RWMutex
+	setMultiYArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}
+	setMultiYReturns struct {
+		result1 error
+	}
+	setMultiYReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SizeStub        func() (int, int)
+	sizeMutex       sync.RWMutex
+	sizeArgsForCall []struct {
+	}
+	sizeReturns struct {
+		result1 int
+		result2 int
+	}
+	sizeReturnsOnCall map[int]struct {
+		result1 int
+		result2 int
+	}
+	TransposeStub        func()
+	transposeMutex       sync.RWMutex
+	transposeArgsForCall []struct {
+	}
+	UndoStub        func() error
+	undoMutex       sync.RWMutex
+	undoArgsForCall []struct {
+	}
+	undoReturns struct {
+		result1 error
+	}
+	undoReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeImageEditor) AddLayer(arg1 string) error {
+	fake.addLayerMutex.Lock()
+	ret, specificReturn := fake.addLayerReturnsOnCall[len(fake.addLayerArgsForCall)]
+	fake.addLayerArgsForCall = append(fake.addLayerArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("AddLayer", []interface{}{arg1})
+	fake.addLayerMutex.Unlock()
+	if fake.AddLayerStub != nil {
+		return fake.AddLayerStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.addLayerReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) AddLayerCallCount() int {
+	fake.addLayerMutex.RLock()
+	defer fake.addLayerMutex.RUnlock()
+	return len(fake.addLayerArgsForCall)
+}
+
+func (fake *FakeImageEditor) AddLayerCalls(stub func(string) error) {
+	fake.addLayerMutex.Lock()
+	defer fake.addLayerMutex.Unlock()
+	fake.AddLayerStub = stub
+}
+
+func (fake *FakeImageEditor) AddLayerArgsForCall(i int) string {
+	fake.addLayerMutex.RLock()
+	defer fake.addLayerMutex.RUnlock()
+	argsForCall := fake.addLayerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) AddLayerReturns(result1 error) {
+	fake.addLayerMutex.Lock()
+	defer fake.addLayerMutex.Unlock()
+	fake.AddLayerStub = nil
+	fake.addLayerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) AddLayerReturnsOnCall(i int, result1 error) {
+	fake.addLayerMutex.Lock()
+	defer fake.addLayerMutex.Unlock()
+	fake.AddLayerStub = nil
+	if fake.addLayerReturnsOnCall == nil {
+		fake.addLayerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addLayerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Box(arg1 int, arg2 int, arg3 int, arg4 int, arg5 string) error {
+	fake.boxMutex.Lock()
+	ret, specificReturn := fake.boxReturnsOnCall[len(fake.boxArgsForCall)]
+	fake.boxArgsForCall = append(fake.boxArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("Box", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.boxMutex.Unlock()
+	if fake.BoxStub != nil {
+		return fake.BoxStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.boxReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) BoxCallCount() int {
+	fake.boxMutex.RLock()
+	defer fake.boxMutex.RUnlock()
+	return len(fake.boxArgsForCall)
+}
+
+func (fake *FakeImageEditor) BoxCalls(stub func(int, int, int, int, string) error) {
+	fake.boxMutex.Lock()
+	defer fake.boxMutex.Unlock()
+	fake.BoxStub = stub
+}
+
+func (fake *FakeImageEditor) BoxArgsForCall(i int) (int, int, int, int, string) {
+	fake.boxMutex.RLock()
+	defer fake.boxMutex.RUnlock()
+	argsForCall := fake.boxArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageEditor) BoxReturns(result1 error) {
+	fake.boxMutex.Lock()
+	defer fake.boxMutex.Unlock()
+	fake.BoxStub = nil
+	fake.boxReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) BoxReturnsOnCall(i int, result1 error) {
+	fake.boxMutex.Lock()
+	defer fake.boxMutex.Unlock()
+	fake.BoxStub = nil
+	if fake.boxReturnsOnCall == nil {
+		fake.boxReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.boxReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Circle(arg1 int, arg2 int, arg3 int, arg4 string) error {
+	fake.circleMutex.Lock()
+	ret, specificReturn := fake.circleReturnsOnCall[len(fake.circleArgsForCall)]
+	fake.circleArgsForCall = append(fake.circleArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Circle", []interface{}{arg1, arg2, arg3, arg4})
+	fake.circleMutex.Unlock()
+	if fake.CircleStub != nil {
+		return fake.CircleStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.circleReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) CircleCallCount() int {
+	fake.circleMutex.RLock()
+	defer fake.circleMutex.RUnlock()
+	return len(fake.circleArgsForCall)
+}
+
+func (fake *FakeImageEditor) CircleCalls(stub func(int, int, int, string) error) {
+	fake.circleMutex.Lock()
+	defer fake.circleMutex.Unlock()
+	fake.CircleStub = stub
+}
+
+func (fake *FakeImageEditor) CircleArgsForCall(i int) (int, int, int, string) {
+	fake.circleMutex.RLock()
+	defer fake.circleMutex.RUnlock()
+	argsForCall := fake.circleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageEditor) CircleReturns(result1 error) {
+	fake.circleMutex.Lock()
+	defer fake.circleMutex.Unlock()
+	fake.CircleStub = nil
+	fake.circleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CircleReturnsOnCall(i int, result1 error) {
+	fake.circleMutex.Lock()
+	defer fake.circleMutex.Unlock()
+	fake.CircleStub = nil
+	if fake.circleReturnsOnCall == nil {
+		fake.circleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.circleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Clear() {
+	fake.clearMutex.Lock()
+	fake.clearArgsForCall = append(fake.clearArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Clear", []interface{}{})
+	fake.clearMutex.Unlock()
+	if fake.ClearStub != nil {
+		fake.ClearStub()
+	}
+}
+
+func (fake *FakeImageEditor) ClearCallCount() int {
+	fake.clearMutex.RLock()
+	defer fake.clearMutex.RUnlock()
+	return len(fake.clearArgsForCall)
+}
+
+func (fake *FakeImageEditor) ClearCalls(stub func()) {
+	fake.clearMutex.Lock()
+	defer fake.clearMutex.Unlock()
+	fake.ClearStub = stub
+}
+
+func (fake *FakeImageEditor) Copy(arg1 int, arg2 int, arg3 int, arg4 int) error {
+	fake.copyMutex.Lock()
+	ret, specificReturn := fake.copyReturnsOnCall[len(fake.copyArgsForCall)]
+	fake.copyArgsForCall = append(fake.copyArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Copy", []interface{}{arg1, arg2, arg3, arg4})
+	fake.copyMutex.Unlock()
+	if fake.CopyStub != nil {
+		return fake.CopyStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.copyReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) CopyCallCount() int {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	return len(fake.copyArgsForCall)
+}
+
+func (fake *FakeImageEditor) CopyCalls(stub func(int, int, int, int) error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = stub
+}
+
+func (fake *FakeImageEditor) CopyArgsForCall(i int) (int, int, int, int) {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	argsForCall := fake.copyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageEditor) CopyReturns(result1 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	fake.copyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CopyReturnsOnCall(i int, result1 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	if fake.copyReturnsOnCall == nil {
+		fake.copyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CreateImage(arg1 int, arg2 int) {
+	fake.createImageMutex.Lock()
+	fake.createImageArgsForCall = append(fake.createImageArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("CreateImage", []interface{}{arg1, arg2})
+	fake.createImageMutex.Unlock()
+	if fake.CreateImageStub != nil {
+		fake.CreateImageStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeImageEditor) CreateImageCallCount() int {
+	fake.createImageMutex.RLock()
+	defer fake.createImageMutex.RUnlock()
+	return len(fake.createImageArgsForCall)
+}
+
+func (fake *FakeImageEditor) CreateImageCalls(stub func(int, int)) {
+	fake.createImageMutex.Lock()
+	defer fake.createImageMutex.Unlock()
+	fake.CreateImageStub = stub
+}
+
+func (fake *FakeImageEditor) CreateImageArgsForCall(i int) (int, int) {
+	fake.createImageMutex.RLock()
+	defer fake.createImageMutex.RUnlock()
+	argsForCall := fake.createImageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageEditor) Crop(arg1 int, arg2 int, arg3 int, arg4 int) error {
+	fake.cropMutex.Lock()
+	ret, specificReturn := fake.cropReturnsOnCall[len(fake.cropArgsForCall)]
+	fake.cropArgsForCall = append(fake.cropArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Crop", []interface{}{arg1, arg2, arg3, arg4})
+	fake.cropMutex.Unlock()
+	if fake.CropStub != nil {
+		return fake.CropStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.cropReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) CropCallCount() int {
+	fake.cropMutex.RLock()
+	defer fake.cropMutex.RUnlock()
+	return len(fake.cropArgsForCall)
+}
+
+func (fake *FakeImageEditor) CropCalls(stub func(int, int, int, int) error) {
+	fake.cropMutex.Lock()
+	defer fake.cropMutex.Unlock()
+	fake.CropStub = stub
+}
+
+func (fake *FakeImageEditor) CropArgsForCall(i int) (int, int, int, int) {
+	fake.cropMutex.RLock()
+	defer fake.cropMutex.RUnlock()
+	argsForCall := fake.cropArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageEditor) CropReturns(result1 error) {
+	fake.cropMutex.Lock()
+	defer fake.cropMutex.Unlock()
+	fake.CropStub = nil
+	fake.cropReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CropReturnsOnCall(i int, result1 error) {
+	fake.cropMutex.Lock()
+	defer fake.cropMutex.Unlock()
+	fake.CropStub = nil
+	if fake.cropReturnsOnCall == nil {
+		fake.cropReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cropReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CropToContent() error {
+	fake.cropToContentMutex.Lock()
+	ret, specificReturn := fake.cropToContentReturnsOnCall[len(fake.cropToContentArgsForCall)]
+	fake.cropToContentArgsForCall = append(fake.cropToContentArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CropToContent", []interface{}{})
+	fake.cropToContentMutex.Unlock()
+	if fake.CropToContentStub != nil {
+		return fake.CropToContentStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.cropToContentReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) CropToContentCallCount() int {
+	fake.cropToContentMutex.RLock()
+	defer fake.cropToContentMutex.RUnlock()
+	return len(fake.cropToContentArgsForCall)
+}
+
+func (fake *FakeImageEditor) CropToContentCalls(stub func() error) {
+	fake.cropToContentMutex.Lock()
+	defer fake.cropToContentMutex.Unlock()
+	fake.CropToContentStub = stub
+}
+
+func (fake *FakeImageEditor) CropToContentReturns(result1 error) {
+	fake.cropToContentMutex.Lock()
+	defer fake.cropToContentMutex.Unlock()
+	fake.CropToContentStub = nil
+	fake.cropToContentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) CropToContentReturnsOnCall(i int, result1 error) {
+	fake.cropToContentMutex.Lock()
+	defer fake.cropToContentMutex.Unlock()
+	fake.CropToContentStub = nil
+	if fake.cropToContentReturnsOnCall == nil {
+		fake.cropToContentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cropToContentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Ellipse(arg1 int, arg2 int, arg3 int, arg4 int, arg5 string) error {
+	fake.ellipseMutex.Lock()
+	ret, specificReturn := fake.ellipseReturnsOnCall[len(fake.ellipseArgsForCall)]
+	fake.ellipseArgsForCall = append(fake.ellipseArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("Ellipse", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.ellipseMutex.Unlock()
+	if fake.EllipseStub != nil {
+		return fake.EllipseStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.ellipseReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) EllipseCallCount() int {
+	fake.ellipseMutex.RLock()
+	defer fake.ellipseMutex.RUnlock()
+	return len(fake.ellipseArgsForCall)
+}
+
+func (fake *FakeImageEditor) EllipseCalls(stub func(int, int, int, int, string) error) {
+	fake.ellipseMutex.Lock()
+	defer fake.ellipseMutex.Unlock()
+	fake.EllipseStub = stub
+}
+
+func (fake *FakeImageEditor) EllipseArgsForCall(i int) (int, int, int, int, string) {
+	fake.ellipseMutex.RLock()
+	defer fake.ellipseMutex.RUnlock()
+	argsForCall := fake.ellipseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageEditor) EllipseReturns(result1 error) {
+	fake.ellipseMutex.Lock()
+	defer fake.ellipseMutex.Unlock()
+	fake.EllipseStub = nil
+	fake.ellipseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) EllipseReturnsOnCall(i int, result1 error) {
+	fake.ellipseMutex.Lock()
+	defer fake.ellipseMutex.Unlock()
+	fake.EllipseStub = nil
+	if fake.ellipseReturnsOnCall == nil {
+		fake.ellipseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.ellipseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Fill(arg1 int, arg2 int, arg3 string) error {
+	fake.fillMutex.Lock()
+	ret, specificReturn := fake.fillReturnsOnCall[len(fake.fillArgsForCall)]
+	fake.fillArgsForCall = append(fake.fillArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Fill", []interface{}{arg1, arg2, arg3})
+	fake.fillMutex.Unlock()
+	if fake.FillStub != nil {
+		return fake.FillStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.fillReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) FillCallCount() int {
+	fake.fillMutex.RLock()
+	defer fake.fillMutex.RUnlock()
+	return len(fake.fillArgsForCall)
+}
+
+func (fake *FakeImageEditor) FillCalls(stub func(int, int, string) error) {
+	fake.fillMutex.Lock()
+	defer fake.fillMutex.Unlock()
+	fake.FillStub = stub
+}
+
+func (fake *FakeImageEditor) FillArgsForCall(i int) (int, int, string) {
+	fake.fillMutex.RLock()
+	defer fake.fillMutex.RUnlock()
+	argsForCall := fake.fillArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeImageEditor) FillReturns(result1 error) {
+	fake.fillMutex.Lock()
+	defer fake.fillMutex.Unlock()
+	fake.FillStub = nil
+	fake.fillReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FillReturnsOnCall(i int, result1 error) {
+	fake.fillMutex.Lock()
+	defer fake.fillMutex.Unlock()
+	fake.FillStub = nil
+	if fake.fillReturnsOnCall == nil {
+		fake.fillReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.fillReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FilledCircle(arg1 int, arg2 int, arg3 int, arg4 string) error {
+	fake.filledCircleMutex.Lock()
+	ret, specificReturn := fake.filledCircleReturnsOnCall[len(fake.filledCircleArgsForCall)]
+	fake.filledCircleArgsForCall = append(fake.filledCircleArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("FilledCircle", []interface{}{arg1, arg2, arg3, arg4})
+	fake.filledCircleMutex.Unlock()
+	if fake.FilledCircleStub != nil {
+		return fake.FilledCircleStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.filledCircleReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) FilledCircleCallCount() int {
+	fake.filledCircleMutex.RLock()
+	defer fake.filledCircleMutex.RUnlock()
+	return len(fake.filledCircleArgsForCall)
+}
+
+func (fake *FakeImageEditor) FilledCircleCalls(stub func(int, int, int, string) error) {
+	fake.filledCircleMutex.Lock()
+	defer fake.filledCircleMutex.Unlock()
+	fake.FilledCircleStub = stub
+}
+
+func (fake *FakeImageEditor) FilledCircleArgsForCall(i int) (int, int, int, string) {
+	fake.filledCircleMutex.RLock()
+	defer fake.filledCircleMutex.RUnlock()
+	argsForCall := fake.filledCircleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageEditor) FilledCircleReturns(result1 error) {
+	fake.filledCircleMutex.Lock()
+	defer fake.filledCircleMutex.Unlock()
+	fake.FilledCircleStub = nil
+	fake.filledCircleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FilledCircleReturnsOnCall(i int, result1 error) {
+	fake.filledCircleMutex.Lock()
+	defer fake.filledCircleMutex.Unlock()
+	fake.FilledCircleStub = nil
+	if fake.filledCircleReturnsOnCall == nil {
+		fake.filledCircleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.filledCircleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FilledEllipse(arg1 int, arg2 int, arg3 int, arg4 int, arg5 string) error {
+	fake.filledEllipseMutex.Lock()
+	ret, specificReturn := fake.filledEllipseReturnsOnCall[len(fake.filledEllipseArgsForCall)]
+	fake.filledEllipseArgsForCall = append(fake.filledEllipseArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("FilledEllipse", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.filledEllipseMutex.Unlock()
+	if fake.FilledEllipseStub != nil {
+		return fake.FilledEllipseStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.filledEllipseReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) FilledEllipseCallCount() int {
+	fake.filledEllipseMutex.RLock()
+	defer fake.filledEllipseMutex.RUnlock()
+	return len(fake.filledEllipseArgsForCall)
+}
+
+func (fake *FakeImageEditor) FilledEllipseCalls(stub func(int, int, int, int, string) error) {
+	fake.filledEllipseMutex.Lock()
+	defer fake.filledEllipseMutex.Unlock()
+	fake.FilledEllipseStub = stub
+}
+
+func (fake *FakeImageEditor) FilledEllipseArgsForCall(i int) (int, int, int, int, string) {
+	fake.filledEllipseMutex.RLock()
+	defer fake.filledEllipseMutex.RUnlock()
+	argsForCall := fake.filledEllipseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageEditor) FilledEllipseReturns(result1 error) {
+	fake.filledEllipseMutex.Lock()
+	defer fake.filledEllipseMutex.Unlock()
+	fake.FilledEllipseStub = nil
+	fake.filledEllipseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FilledEllipseReturnsOnCall(i int, result1 error) {
+	fake.filledEllipseMutex.Lock()
+	defer fake.filledEllipseMutex.Unlock()
+	fake.FilledEllipseStub = nil
+	if fake.filledEllipseReturnsOnCall == nil {
+		fake.filledEllipseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.filledEllipseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) FlipHorizontal() {
+	fake.flipHorizontalMutex.Lock()
+	fake.flipHorizontalArgsForCall = append(fake.flipHorizontalArgsForCall, struct {
+	}{})
+	fake.recordInvocation("FlipHorizontal", []interface{}{})
+	fake.flipHorizontalMutex.Unlock()
+	if fake.FlipHorizontalStub != nil {
+		fake.FlipHorizontalStub()
+	}
+}
+
+func (fake *FakeImageEditor) FlipHorizontalCallCount() int {
+	fake.flipHorizontalMutex.RLock()
+	defer fake.flipHorizontalMutex.RUnlock()
+	return len(fake.flipHorizontalArgsForCall)
+}
+
+func (fake *FakeImageEditor) FlipHorizontalCalls(stub func()) {
+	fake.flipHorizontalMutex.Lock()
+	defer fake.flipHorizontalMutex.Unlock()
+	fake.FlipHorizontalStub = stub
+}
+
+func (fake *FakeImageEditor) FlipVertical() {
+	fake.flipVerticalMutex.Lock()
+	fake.flipVerticalArgsForCall = append(fake.flipVerticalArgsForCall, struct {
+	}{})
+	fake.recordInvocation("FlipVertical", []interface{}{})
+	fake.flipVerticalMutex.Unlock()
+	if fake.FlipVerticalStub != nil {
+		fake.FlipVerticalStub()
+	}
+}
+
+func (fake *FakeImageEditor) FlipVerticalCallCount() int {
+	fake.flipVerticalMutex.RLock()
+	defer fake.flipVerticalMutex.RUnlock()
+	return len(fake.flipVerticalArgsForCall)
+}
+
+func (fake *FakeImageEditor) FlipVerticalCalls(stub func()) {
+	fake.flipVerticalMutex.Lock()
+	defer fake.flipVerticalMutex.Unlock()
+	fake.FlipVerticalStub = stub
+}
+
+func (fake *FakeImageEditor) HideLayer(arg1 string, arg2 bool) error {
+	fake.hideLayerMutex.Lock()
+	ret, specificReturn := fake.hideLayerReturnsOnCall[len(fake.hideLayerArgsForCall)]
+	fake.hideLayerArgsForCall = append(fake.hideLayerArgsForCall, struct {
+		arg1 string
+		arg2 bool
+	}{arg1, arg2})
+	fake.recordInvocation("HideLayer", []interface{}{arg1, arg2})
+	fake.hideLayerMutex.Unlock()
+	if fake.HideLayerStub != nil {
+		return fake.HideLayerStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.hideLayerReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) HideLayerCallCount() int {
+	fake.hideLayerMutex.RLock()
+	defer fake.hideLayerMutex.RUnlock()
+	return len(fake.hideLayerArgsForCall)
+}
+
+func (fake *FakeImageEditor) HideLayerCalls(stub func(string, bool) error) {
+	fake.hideLayerMutex.Lock()
+	defer fake.hideLayerMutex.Unlock()
+	fake.HideLayerStub = stub
+}
+
+func (fake *FakeImageEditor) HideLayerArgsForCall(i int) (string, bool) {
+	fake.hideLayerMutex.RLock()
+	defer fake.hideLayerMutex.RUnlock()
+	argsForCall := fake.hideLayerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageEditor) HideLayerReturns(result1 error) {
+	fake.hideLayerMutex.Lock()
+	defer fake.hideLayerMutex.Unlock()
+	fake.HideLayerStub = nil
+	fake.hideLayerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) HideLayerReturnsOnCall(i int, result1 error) {
+	fake.hideLayerMutex.Lock()
+	defer fake.hideLayerMutex.Unlock()
+	fake.HideLayerStub = nil
+	if fake.hideLayerReturnsOnCall == nil {
+		fake.hideLayerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.hideLayerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Line(arg1 int, arg2 int, arg3 int, arg4 int, arg5 string) error {
+	fake.lineMutex.Lock()
+	ret, specificReturn := fake.lineReturnsOnCall[len(fake.lineArgsForCall)]
+	fake.lineArgsForCall = append(fake.lineArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("Line", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.lineMutex.Unlock()
+	if fake.LineStub != nil {
+		return fake.LineStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.lineReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) LineCallCount() int {
+	fake.lineMutex.RLock()
+	defer fake.lineMutex.RUnlock()
+	return len(fake.lineArgsForCall)
+}
+
+func (fake *FakeImageEditor) LineCalls(stub func(int, int, int, int, string) error) {
+	fake.lineMutex.Lock()
+	defer fake.lineMutex.Unlock()
+	fake.LineStub = stub
+}
+
+func (fake *FakeImageEditor) LineArgsForCall(i int) (int, int, int, int, string) {
+	fake.lineMutex.RLock()
+	defer fake.lineMutex.RUnlock()
+	argsForCall := fake.lineArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageEditor) LineReturns(result1 error) {
+	fake.lineMutex.Lock()
+	defer fake.lineMutex.Unlock()
+	fake.LineStub = nil
+	fake.lineReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) LineReturnsOnCall(i int, result1 error) {
+	fake.lineMutex.Lock()
+	defer fake.lineMutex.Unlock()
+	fake.LineStub = nil
+	if fake.lineReturnsOnCall == nil {
+		fake.lineReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.lineReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Load(arg1 io.Reader) error {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+		arg1 io.Reader
+	}{arg1})
+	fake.recordInvocation("Load", []interface{}{arg1})
+	fake.loadMutex.Unlock()
+	if fake.LoadStub != nil {
+		return fake.LoadStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.loadReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeImageEditor) LoadCalls(stub func(io.Reader) error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *FakeImageEditor) LoadArgsForCall(i int) io.Reader {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	argsForCall := fake.loadArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) LoadReturns(result1 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) LoadReturnsOnCall(i int, result1 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) MergeLayer(arg1 string) error {
+	fake.mergeLayerMutex.Lock()
+	ret, specificReturn := fake.mergeLayerReturnsOnCall[len(fake.mergeLayerArgsForCall)]
+	fake.mergeLayerArgsForCall = append(fake.mergeLayerArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("MergeLayer", []interface{}{arg1})
+	fake.mergeLayerMutex.Unlock()
+	if fake.MergeLayerStub != nil {
+		return fake.MergeLayerStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.mergeLayerReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) MergeLayerCallCount() int {
+	fake.mergeLayerMutex.RLock()
+	defer fake.mergeLayerMutex.RUnlock()
+	return len(fake.mergeLayerArgsForCall)
+}
+
+func (fake *FakeImageEditor) MergeLayerCalls(stub func(string) error) {
+	fake.mergeLayerMutex.Lock()
+	defer fake.mergeLayerMutex.Unlock()
+	fake.MergeLayerStub = stub
+}
+
+func (fake *FakeImageEditor) MergeLayerArgsForCall(i int) string {
+	fake.mergeLayerMutex.RLock()
+	defer fake.mergeLayerMutex.RUnlock()
+	argsForCall := fake.mergeLayerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) MergeLayerReturns(result1 error) {
+	fake.mergeLayerMutex.Lock()
+	defer fake.mergeLayerMutex.Unlock()
+	fake.MergeLayerStub = nil
+	fake.mergeLayerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) MergeLayerReturnsOnCall(i int, result1 error) {
+	fake.mergeLayerMutex.Lock()
+	defer fake.mergeLayerMutex.Unlock()
+	fake.MergeLayerStub = nil
+	if fake.mergeLayerReturnsOnCall == nil {
+		fake.mergeLayerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.mergeLayerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Move(arg1 int, arg2 int, arg3 int, arg4 int, arg5 int, arg6 int) error {
+	fake.moveMutex.Lock()
+	ret, specificReturn := fake.moveReturnsOnCall[len(fake.moveArgsForCall)]
+	fake.moveArgsForCall = append(fake.moveArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 int
+		arg4 int
+		arg5 int
+		arg6 int
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.recordInvocation("Move", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.moveMutex.Unlock()
+	if fake.MoveStub != nil {
+		return fake.MoveStub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.moveReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) MoveCallCount() int {
+	fake.moveMutex.RLock()
+	defer fake.moveMutex.RUnlock()
+	return len(fake.moveArgsForCall)
+}
+
+func (fake *FakeImageEditor) MoveCalls(stub func(int, int, int, int, int, int) error) {
+	fake.moveMutex.Lock()
+	defer fake.moveMutex.Unlock()
+	fake.MoveStub = stub
+}
+
+func (fake *FakeImageEditor) MoveArgsForCall(i int) (int, int, int, int, int, int) {
+	fake.moveMutex.RLock()
+	defer fake.moveMutex.RUnlock()
+	argsForCall := fake.moveArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeImageEditor) MoveReturns(result1 error) {
+	fake.moveMutex.Lock()
+	defer fake.moveMutex.Unlock()
+	fake.MoveStub = nil
+	fake.moveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) MoveReturnsOnCall(i int, result1 error) {
+	fake.moveMutex.Lock()
+	defer fake.moveMutex.Unlock()
+	fake.MoveStub = nil
+	if fake.moveReturnsOnCall == nil {
+		fake.moveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.moveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) MoveLayer(arg1 string, arg2 int) error {
+	fake.moveLayerMutex.Lock()
+	ret, specificReturn := fake.moveLayerReturnsOnCall[len(fake.moveLayerArgsForCall)]
+	fake.moveLayerArgsForCall = append(fake.moveLayerArgsForCall, struct {
+		arg1 string
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("MoveLayer", []interface{}{arg1, arg2})
+	fake.moveLayerMutex.Unlock()
+	if fake.MoveLayerStub != nil {
+		return fake.MoveLayerStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.moveLayerReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) MoveLayerCallCount() int {
+	fake.moveLayerMutex.RLock()
+	defer fake.moveLayerMutex.RUnlock()
+	return len(fake.moveLayerArgsForCall)
+}
+
+func (fake *FakeImageEditor) MoveLayerCalls(stub func(string, int) error) {
+	fake.moveLayerMutex.Lock()
+	defer fake.moveLayerMutex.Unlock()
+	fake.MoveLayerStub = stub
+}
+
+func (fake *FakeImageEditor) MoveLayerArgsForCall(i int) (string, int) {
+	fake.moveLayerMutex.RLock()
+	defer fake.moveLayerMutex.RUnlock()
+	argsForCall := fake.moveLayerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageEditor) MoveLayerReturns(result1 error) {
+	fake.moveLayerMutex.Lock()
+	defer fake.moveLayerMutex.Unlock()
+	fake.MoveLayerStub = nil
+	fake.moveLayerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) MoveLayerReturnsOnCall(i int, result1 error) {
+	fake.moveLayerMutex.Lock()
+	defer fake.moveLayerMutex.Unlock()
+	fake.MoveLayerStub = nil
+	if fake.moveLayerReturnsOnCall == nil {
+		fake.moveLayerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.moveLayerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PNG(arg1 io.Writer, arg2 int) error {
+	fake.pNGMutex.Lock()
+	ret, specificReturn := fake.pNGReturnsOnCall[len(fake.pNGArgsForCall)]
+	fake.pNGArgsForCall = append(fake.pNGArgsForCall, struct {
+		arg1 io.Writer
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("PNG", []interface{}{arg1, arg2})
+	fake.pNGMutex.Unlock()
+	if fake.PNGStub != nil {
+		return fake.PNGStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pNGReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) PNGCallCount() int {
+	fake.pNGMutex.RLock()
+	defer fake.pNGMutex.RUnlock()
+	return len(fake.pNGArgsForCall)
+}
+
+func (fake *FakeImageEditor) PNGCalls(stub func(io.Writer, int) error) {
+	fake.pNGMutex.Lock()
+	defer fake.pNGMutex.Unlock()
+	fake.PNGStub = stub
+}
+
+func (fake *FakeImageEditor) PNGArgsForCall(i int) (io.Writer, int) {
+	fake.pNGMutex.RLock()
+	defer fake.pNGMutex.RUnlock()
+	argsForCall := fake.pNGArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageEditor) PNGReturns(result1 error) {
+	fake.pNGMutex.Lock()
+	defer fake.pNGMutex.Unlock()
+	fake.PNGStub = nil
+	fake.pNGReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PNGReturnsOnCall(i int, result1 error) {
+	fake.pNGMutex.Lock()
+	defer fake.pNGMutex.Unlock()
+	fake.PNGStub = nil
+	if fake.pNGReturnsOnCall == nil {
+		fake.pNGReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pNGReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Paste(arg1 int, arg2 int) error {
+	fake.pasteMutex.Lock()
+	ret, specificReturn := fake.pasteReturnsOnCall[len(fake.pasteArgsForCall)]
+	fake.pasteArgsForCall = append(fake.pasteArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("Paste", []interface{}{arg1, arg2})
+	fake.pasteMutex.Unlock()
+	if fake.PasteStub != nil {
+		return fake.PasteStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pasteReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) PasteCallCount() int {
+	fake.pasteMutex.RLock()
+	defer fake.pasteMutex.RUnlock()
+	return len(fake.pasteArgsForCall)
+}
+
+func (fake *FakeImageEditor) PasteCalls(stub func(int, int) error) {
+	fake.pasteMutex.Lock()
+	defer fake.pasteMutex.Unlock()
+	fake.PasteStub = stub
+}
+
+func (fake *FakeImageEditor) PasteArgsForCall(i int) (int, int) {
+	fake.pasteMutex.RLock()
+	defer fake.pasteMutex.RUnlock()
+	argsForCall := fake.pasteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageEditor) PasteReturns(result1 error) {
+	fake.pasteMutex.Lock()
+	defer fake.pasteMutex.Unlock()
+	fake.PasteStub = nil
+	fake.pasteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PasteReturnsOnCall(i int, result1 error) {
+	fake.pasteMutex.Lock()
+	defer fake.pasteMutex.Unlock()
+	fake.PasteStub = nil
+	if fake.pasteReturnsOnCall == nil {
+		fake.pasteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pasteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PastePicture(arg1 interface{}, arg2 int, arg3 int) error {
+	fake.pastePictureMutex.Lock()
+	ret, specificReturn := fake.pastePictureReturnsOnCall[len(fake.pastePictureArgsForCall)]
+	fake.pastePictureArgsForCall = append(fake.pastePictureArgsForCall, struct {
+		arg1 interface{}
+		arg2 int
+		arg3 int
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("PastePicture", []interface{}{arg1, arg2, arg3})
+	fake.pastePictureMutex.Unlock()
+	if fake.PastePictureStub != nil {
+		return fake.PastePictureStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pastePictureReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) PastePictureCallCount() int {
+	fake.pastePictureMutex.RLock()
+	defer fake.pastePictureMutex.RUnlock()
+	return len(fake.pastePictureArgsForCall)
+}
+
+func (fake *FakeImageEditor) PastePictureCalls(stub func(interface{}, int, int) error) {
+	fake.pastePictureMutex.Lock()
+	defer fake.pastePictureMutex.Unlock()
+	fake.PastePictureStub = stub
+}
+
+func (fake *FakeImageEditor) PastePictureArgsForCall(i int) (interface{}, int, int) {
+	fake.pastePictureMutex.RLock()
+	defer fake.pastePictureMutex.RUnlock()
+	argsForCall := fake.pastePictureArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeImageEditor) PastePictureReturns(result1 error) {
+	fake.pastePictureMutex.Lock()
+	defer fake.pastePictureMutex.Unlock()
+	fake.PastePictureStub = nil
+	fake.pastePictureReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PastePictureReturnsOnCall(i int, result1 error) {
+	fake.pastePictureMutex.Lock()
+	defer fake.pastePictureMutex.Unlock()
+	fake.PastePictureStub = nil
+	if fake.pastePictureReturnsOnCall == nil {
+		fake.pastePictureReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pastePictureReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Picture() interface{} {
+	fake.pictureMutex.Lock()
+	ret, specificReturn := fake.pictureReturnsOnCall[len(fake.pictureArgsForCall)]
+	fake.pictureArgsForCall = append(fake.pictureArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Picture", []interface{}{})
+	fake.pictureMutex.Unlock()
+	if fake.PictureStub != nil {
+		return fake.PictureStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pictureReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) PictureCallCount() int {
+	fake.pictureMutex.RLock()
+	defer fake.pictureMutex.RUnlock()
+	return len(fake.pictureArgsForCall)
+}
+
+func (fake *FakeImageEditor) PictureCalls(stub func() interface{}) {
+	fake.pictureMutex.Lock()
+	defer fake.pictureMutex.Unlock()
+	fake.PictureStub = stub
+}
+
+func (fake *FakeImageEditor) PictureReturns(result1 interface{}) {
+	fake.pictureMutex.Lock()
+	defer fake.pictureMutex.Unlock()
+	fake.PictureStub = nil
+	fake.pictureReturns = struct {
+		result1 interface{}
+	}{result1}
+}
+
+func (fake *FakeImageEditor) PictureReturnsOnCall(i int, result1 interface{}) {
+	fake.pictureMutex.Lock()
+	defer fake.pictureMutex.Unlock()
+	fake.PictureStub = nil
+	if fake.pictureReturnsOnCall == nil {
+		fake.pictureReturnsOnCall = make(map[int]struct {
+			result1 interface{}
+		})
+	}
+	fake.pictureReturnsOnCall[i] = struct {
+		result1 interface{}
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Rect(arg1 int, arg2 int, arg3 int, arg4 int, arg5 string) error {
+	fake.rectMutex.Lock()
+	ret, specificReturn := fake.rectReturnsOnCall[len(fake.rectArgsForCall)]
+	fake.rectArgsForCall = append(fake.rectArgsForCall, struct {
 		arg1 int
 		arg2 int
+		arg3 int
+		arg4 int
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("Rect", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.rectMutex.Unlock()
+	if fake.RectStub != nil {
+		return fake.RectStub(arg1, arg2, arg3, arg4, arg5)
 	}
-	PrettyStub        func() string
-	prettyMutex       sync.RWMutex
-	prettyArgsForCall []struct {
+	if specificReturn {
+		return ret.result1
 	}
-	prettyReturns struct {
-		result1 string
+	fakeReturns := fake.rectReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) RectCallCount() int {
+	fake.rectMutex.RLock()
+	defer fake.rectMutex.RUnlock()
+	return len(fake.rectArgsForCall)
+}
+
+func (fake *FakeImageEditor) RectCalls(stub func(int, int, int, int, string) error) {
+	fake.rectMutex.Lock()
+	defer fake.rectMutex.Unlock()
+	fake.RectStub = stub
+}
+
+func (fake *FakeImageEditor) RectArgsForCall(i int) (int, int, int, int, string) {
+	fake.rectMutex.RLock()
+	defer fake.rectMutex.RUnlock()
+	argsForCall := fake.rectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageEditor) RectReturns(result1 error) {
+	fake.rectMutex.Lock()
+	defer fake.rectMutex.Unlock()
+	fake.RectStub = nil
+	fake.rectReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RectReturnsOnCall(i int, result1 error) {
+	fake.rectMutex.Lock()
+	defer fake.rectMutex.Unlock()
+	fake.RectStub = nil
+	if fake.rectReturnsOnCall == nil {
+		fake.rectReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
 	}
-	prettyReturnsOnCall map[int]struct {
-		result1 string
+	fake.rectReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Redo() error {
+	fake.redoMutex.Lock()
+	ret, specificReturn := fake.redoReturnsOnCall[len(fake.redoArgsForCall)]
+	fake.redoArgsForCall = append(fake.redoArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Redo", []interface{}{})
+	fake.redoMutex.Unlock()
+	if fake.RedoStub != nil {
+		return fake.RedoStub()
 	}
-	SetStub        func(int, int, string) error
-	setMutex       sync.RWMutex
-	setArgsForCall []struct {
-		arg1 int
-		arg2 int
-		arg3 string
+	if specificReturn {
+		return ret.result1
 	}
-	setReturns struct {
+	fakeReturns := fake.redoReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) RedoCallCount() int {
+	fake.redoMutex.RLock()
+	defer fake.redoMutex.RUnlock()
+	return len(fake.redoArgsForCall)
+}
+
+func (fake *FakeImageEditor) RedoCalls(stub func() error) {
+	fake.redoMutex.Lock()
+	defer fake.redoMutex.Unlock()
+	fake.RedoStub = stub
+}
+
+func (fake *FakeImageEditor) RedoReturns(result1 error) {
+	fake.redoMutex.Lock()
+	defer fake.redoMutex.Unlock()
+	fake.RedoStub = nil
+	fake.redoReturns = struct {
 		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RedoReturnsOnCall(i int, result1 error) {
+	fake.redoMutex.Lock()
+	defer fake.redoMutex.Unlock()
+	fake.RedoStub = nil
+	if fake.redoReturnsOnCall == nil {
+		fake.redoReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
 	}
-	setReturnsOnCall map[int]struct {
+	fake.redoReturnsOnCall[i] = struct {
 		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Render(arg1 io.Writer) error {
+	fake.renderMutex.Lock()
+	ret, specificReturn := fake.renderReturnsOnCall[len(fake.renderArgsForCall)]
+	fake.renderArgsForCall = append(fake.renderArgsForCall, struct {
+		arg1 io.Writer
+	}{arg1})
+	fake.recordInvocation("Render", []interface{}{arg1})
+	fake.renderMutex.Unlock()
+	if fake.RenderStub != nil {
+		return fake.RenderStub(arg1)
 	}
-	SetMultiXStub        func(int, int, int, string) error
-	setMultiXMutex       sync.RWMutex
-	setMultiXArgsForCall []struct {
-		arg1 int
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.renderReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) RenderCallCount() int {
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
+	return len(fake.renderArgsForCall)
+}
+
+func (fake *FakeImageEditor) RenderCalls(stub func(io.Writer) error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = stub
+}
+
+func (fake *FakeImageEditor) RenderArgsForCall(i int) io.Writer {
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
+	argsForCall := fake.renderArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) RenderReturns(result1 error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = nil
+	fake.renderReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RenderReturnsOnCall(i int, result1 error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = nil
+	if fake.renderReturnsOnCall == nil {
+		fake.renderReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.renderReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RenderRegion(arg1 io.Writer, arg2 int, arg3 int, arg4 int, arg5 int, arg6 bool, arg7 int) error {
+	fake.renderRegionMutex.Lock()
+	ret, specificReturn := fake.renderRegionReturnsOnCall[len(fake.renderRegionArgsForCall)]
+	fake.renderRegionArgsForCall = append(fake.renderRegionArgsForCall, struct {
+		arg1 io.Writer
 		arg2 int
 		arg3 int
-		arg4 string
+		arg4 int
+		arg5 int
+		arg6 bool
+		arg7 int
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.recordInvocation("RenderRegion", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.renderRegionMutex.Unlock()
+	if fake.RenderRegionStub != nil {
+		return fake.RenderRegionStub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
-	setMultiXReturns struct {
-		result1 error
+	if specificReturn {
+		return ret.result1
 	}
-	setMultiXReturnsOnCall map[int]struct {
+	fakeReturns := fake.renderRegionReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) RenderRegionCallCount() int {
+	fake.renderRegionMutex.RLock()
+	defer fake.renderRegionMutex.RUnlock()
+	return len(fake.renderRegionArgsForCall)
+}
+
+func (fake *FakeImageEditor) RenderRegionCalls(stub func(io.Writer, int, int, int, int, bool, int) error) {
+	fake.renderRegionMutex.Lock()
+	defer fake.renderRegionMutex.Unlock()
+	fake.RenderRegionStub = stub
+}
+
+func (fake *FakeImageEditor) RenderRegionArgsForCall(i int) (io.Writer, int, int, int, int, bool, int) {
+	fake.renderRegionMutex.RLock()
+	defer fake.renderRegionMutex.RUnlock()
+	argsForCall := fake.renderRegionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+}
+
+func (fake *FakeImageEditor) RenderRegionReturns(result1 error) {
+	fake.renderRegionMutex.Lock()
+	defer fake.renderRegionMutex.Unlock()
+	fake.RenderRegionStub = nil
+	fake.renderRegionReturns = struct {
 		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RenderRegionReturnsOnCall(i int, result1 error) {
+	fake.renderRegionMutex.Lock()
+	defer fake.renderRegionMutex.Unlock()
+	fake.RenderRegionStub = nil
+	if fake.renderRegionReturnsOnCall == nil {
+		fake.renderRegionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
 	}
-	SetMultiYStub        func(int, int, int, string) error
-	setMultiYMutex       sync.RWMutex
-	setMultiYArgsForCall []struct {
+	fake.renderRegionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Resize(arg1 int, arg2 int, arg3 int, arg4 int) {
+	fake.resizeMutex.Lock()
+	fake.resizeArgsForCall = append(fake.resizeArgsForCall, struct {
 		arg1 int
 		arg2 int
 		arg3 int
-		arg4 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Resize", []interface{}{arg1, arg2, arg3, arg4})
+	fake.resizeMutex.Unlock()
+	if fake.ResizeStub != nil {
+		fake.ResizeStub(arg1, arg2, arg3, arg4)
 	}
-	setMultiYReturns struct {
-		result1 error
+}
+
+func (fake *FakeImageEditor) ResizeCallCount() int {
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
+	return len(fake.resizeArgsForCall)
+}
+
+func (fake *FakeImageEditor) ResizeCalls(stub func(int, int, int, int)) {
+	fake.resizeMutex.Lock()
+	defer fake.resizeMutex.Unlock()
+	fake.ResizeStub = stub
+}
+
+func (fake *FakeImageEditor) ResizeArgsForCall(i int) (int, int, int, int) {
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
+	argsForCall := fake.resizeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageEditor) Rotate(arg1 int) error {
+	fake.rotateMutex.Lock()
+	ret, specificReturn := fake.rotateReturnsOnCall[len(fake.rotateArgsForCall)]
+	fake.rotateArgsForCall = append(fake.rotateArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.recordInvocation("Rotate", []interface{}{arg1})
+	fake.rotateMutex.Unlock()
+	if fake.RotateStub != nil {
+		return fake.RotateStub(arg1)
 	}
-	setMultiYReturnsOnCall map[int]struct {
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.rotateReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) RotateCallCount() int {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	return len(fake.rotateArgsForCall)
+}
+
+func (fake *FakeImageEditor) RotateCalls(stub func(int) error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = stub
+}
+
+func (fake *FakeImageEditor) RotateArgsForCall(i int) int {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	argsForCall := fake.rotateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) RotateReturns(result1 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	fake.rotateReturns = struct {
 		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) RotateReturnsOnCall(i int, result1 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	if fake.rotateReturnsOnCall == nil {
+		fake.rotateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
 	}
-	invocations      map[string][][]interface{}
-	invocationsMutex sync.RWMutex
+	fake.rotateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeImageEditor) Clear() {
-	fake.clearMutex.Lock()
-	fake.clearArgsForCall = append(fake.clearArgsForCall, struct {
-	}{})
-	fake.recordInvocation("Clear", []interface{}{})
-	fake.clearMutex.Unlock()
-	if fake.ClearStub != nil {
-		fake.ClearStub()
+func (fake *FakeImageEditor) Save(arg1 io.Writer) error {
+	fake.saveMutex.Lock()
+	ret, specificReturn := fake.saveReturnsOnCall[len(fake.saveArgsForCall)]
+	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
+		arg1 io.Writer
+	}{arg1})
+	fake.recordInvocation("Save", []interface{}{arg1})
+	fake.saveMutex.Unlock()
+	if fake.SaveStub != nil {
+		return fake.SaveStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	fakeReturns := fake.saveReturns
+	return fakeReturns.result1
 }
 
-func (fake *FakeImageEditor) ClearCallCount() int {
-	fake.clearMutex.RLock()
-	defer fake.clearMutex.RUnlock()
-	return len(fake.clearArgsForCall)
+func (fake *FakeImageEditor) SaveCallCount() int {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return len(fake.saveArgsForCall)
 }
 
-func (fake *FakeImageEditor) ClearCalls(stub func()) {
-	fake.clearMutex.Lock()
-	defer fake.clearMutex.Unlock()
-	fake.ClearStub = stub
+func (fake *FakeImageEditor) SaveCalls(stub func(io.Writer) error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = stub
 }
 
-func (fake *FakeImageEditor) CreateImage(arg1 int, arg2 int) {
-	fake.createImageMutex.Lock()
-	fake.createImageArgsForCall = append(fake.createImageArgsForCall, struct {
+func (fake *FakeImageEditor) SaveArgsForCall(i int) io.Writer {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	argsForCall := fake.saveArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) SaveReturns(result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	fake.saveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) SaveReturnsOnCall(i int, result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	if fake.saveReturnsOnCall == nil {
+		fake.saveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) Scale(arg1 int, arg2 int) {
+	fake.scaleMutex.Lock()
+	fake.scaleArgsForCall = append(fake.scaleArgsForCall, struct {
 		arg1 int
 		arg2 int
 	}{arg1, arg2})
-	fake.recordInvocation("CreateImage", []interface{}{arg1, arg2})
-	fake.createImageMutex.Unlock()
-	if fake.CreateImageStub != nil {
-		fake.CreateImageStub(arg1, arg2)
+	fake.recordInvocation("Scale", []interface{}{arg1, arg2})
+	fake.scaleMutex.Unlock()
+	if fake.ScaleStub != nil {
+		fake.ScaleStub(arg1, arg2)
 	}
 }
 
-func (fake *FakeImageEditor) CreateImageCallCount() int {
-	fake.createImageMutex.RLock()
-	defer fake.createImageMutex.RUnlock()
-	return len(fake.createImageArgsForCall)
+func (fake *FakeImageEditor) ScaleCallCount() int {
+	fake.scaleMutex.RLock()
+	defer fake.scaleMutex.RUnlock()
+	return len(fake.scaleArgsForCall)
 }
 
-func (fake *FakeImageEditor) CreateImageCalls(stub func(int, int)) {
-	fake.createImageMutex.Lock()
-	defer fake.createImageMutex.Unlock()
-	fake.CreateImageStub = stub
+func (fake *FakeImageEditor) ScaleCalls(stub func(int, int)) {
+	fake.scaleMutex.Lock()
+	defer fake.scaleMutex.Unlock()
+	fake.ScaleStub = stub
 }
 
-func (fake *FakeImageEditor) CreateImageArgsForCall(i int) (int, int) {
-	fake.createImageMutex.RLock()
-	defer fake.createImageMutex.RUnlock()
-	argsForCall := fake.createImageArgsForCall[i]
+func (fake *FakeImageEditor) ScaleArgsForCall(i int) (int, int) {
+	fake.scaleMutex.RLock()
+	defer fake.scaleMutex.RUnlock()
+	argsForCall := fake.scaleArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeImageEditor) Pretty() string {
-	fake.prettyMutex.Lock()
-	ret, specificReturn := fake.prettyReturnsOnCall[len(fake.prettyArgsForCall)]
-	fake.prettyArgsForCall = append(fake.prettyArgsForCall, struct {
-	}{})
-	fake.recordInvocation("Pretty", []interface{}{})
-	fake.prettyMutex.Unlock()
-	if fake.PrettyStub != nil {
-		return fake.PrettyStub()
+func (fake *FakeImageEditor) SelectLayer(arg1 string) error {
+	fake.selectLayerMutex.Lock()
+	ret, specificReturn := fake.selectLayerReturnsOnCall[len(fake.selectLayerArgsForCall)]
+	fake.selectLayerArgsForCall = append(fake.selectLayerArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("SelectLayer", []interface{}{arg1})
+	fake.selectLayerMutex.Unlock()
+	if fake.SelectLayerStub != nil {
+		return fake.SelectLayerStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.prettyReturns
+	fakeReturns := fake.selectLayerReturns
 	return fakeReturns.result1
 }
 
-func (fake *FakeImageEditor) PrettyCallCount() int {
-	fake.prettyMutex.RLock()
-	defer fake.prettyMutex.RUnlock()
-	return len(fake.prettyArgsForCall)
+func (fake *FakeImageEditor) SelectLayerCallCount() int {
+	fake.selectLayerMutex.RLock()
+	defer fake.selectLayerMutex.RUnlock()
+	return len(fake.selectLayerArgsForCall)
 }
 
-func (fake *FakeImageEditor) PrettyCalls(stub func() string) {
-	fake.prettyMutex.Lock()
-	defer fake.prettyMutex.Unlock()
-	fake.PrettyStub = stub
+func (fake *FakeImageEditor) SelectLayerCalls(stub func(string) error) {
+	fake.selectLayerMutex.Lock()
+	defer fake.selectLayerMutex.Unlock()
+	fake.SelectLayerStub = stub
 }
 
-func (fake *FakeImageEditor) PrettyReturns(result1 string) {
-	fake.prettyMutex.Lock()
-	defer fake.prettyMutex.Unlock()
-	fake.PrettyStub = nil
-	fake.prettyReturns = struct {
-		result1 string
+func (fake *FakeImageEditor) SelectLayerArgsForCall(i int) string {
+	fake.selectLayerMutex.RLock()
+	defer fake.selectLayerMutex.RUnlock()
+	argsForCall := fake.selectLayerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageEditor) SelectLayerReturns(result1 error) {
+	fake.selectLayerMutex.Lock()
+	defer fake.selectLayerMutex.Unlock()
+	fake.SelectLayerStub = nil
+	fake.selectLayerReturns = struct {
+		result1 error
 	}{result1}
 }
 
-func (fake *FakeImageEditor) PrettyReturnsOnCall(i int, result1 string) {
-	fake.prettyMutex.Lock()
-	defer fake.prettyMutex.Unlock()
-	fake.PrettyStub = nil
-	if fake.prettyReturnsOnCall == nil {
-		fake.prettyReturnsOnCall = make(map[int]struct {
-			result1 string
+func (fake *FakeImageEditor) SelectLayerReturnsOnCall(i int, result1 error) {
+	fake.selectLayerMutex.Lock()
+	defer fake.selectLayerMutex.Unlock()
+	fake.SelectLayerStub = nil
+	if fake.selectLayerReturnsOnCall == nil {
+		fake.selectLayerReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.prettyReturnsOnCall[i] = struct {
-		result1 string
+	fake.selectLayerReturnsOnCall[i] = struct {
+		result1 error
 	}{result1}
 }
 
@@ -368,21 +2459,217 @@ func (fake *FakeImageEditor) SetMultiYReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeImageEditor) Size() (int, int) {
+	fake.sizeMutex.Lock()
+	ret, specificReturn := fake.sizeReturnsOnCall[len(fake.sizeArgsForCall)]
+	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Size", []interface{}{})
+	fake.sizeMutex.Unlock()
+	if fake.SizeStub != nil {
+		return fake.SizeStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.sizeReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageEditor) SizeCallCount() int {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return len(fake.sizeArgsForCall)
+}
+
+func (fake *FakeImageEditor) SizeCalls(stub func() (int, int)) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = stub
+}
+
+func (fake *FakeImageEditor) SizeReturns(result1 int, result2 int) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	fake.sizeReturns = struct {
+		result1 int
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *FakeImageEditor) SizeReturnsOnCall(i int, result1 int, result2 int) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	if fake.sizeReturnsOnCall == nil {
+		fake.sizeReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 int
+		})
+	}
+	fake.sizeReturnsOnCall[i] = struct {
+		result1 int
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *FakeImageEditor) Transpose() {
+	fake.transposeMutex.Lock()
+	fake.transposeArgsForCall = append(fake.transposeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Transpose", []interface{}{})
+	fake.transposeMutex.Unlock()
+	if fake.TransposeStub != nil {
+		fake.TransposeStub()
+	}
+}
+
+func (fake *FakeImageEditor) TransposeCallCount() int {
+	fake.transposeMutex.RLock()
+	defer fake.transposeMutex.RUnlock()
+	return len(fake.transposeArgsForCall)
+}
+
+func (fake *FakeImageEditor) TransposeCalls(stub func()) {
+	fake.transposeMutex.Lock()
+	defer fake.transposeMutex.Unlock()
+	fake.TransposeStub = stub
+}
+
+func (fake *FakeImageEditor) Undo() error {
+	fake.undoMutex.Lock()
+	ret, specificReturn := fake.undoReturnsOnCall[len(fake.undoArgsForCall)]
+	fake.undoArgsForCall = append(fake.undoArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Undo", []interface{}{})
+	fake.undoMutex.Unlock()
+	if fake.UndoStub != nil {
+		return fake.UndoStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.undoReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageEditor) UndoCallCount() int {
+	fake.undoMutex.RLock()
+	defer fake.undoMutex.RUnlock()
+	return len(fake.undoArgsForCall)
+}
+
+func (fake *FakeImageEditor) UndoCalls(stub func() error) {
+	fake.undoMutex.Lock()
+	defer fake.undoMutex.Unlock()
+	fake.UndoStub = stub
+}
+
+func (fake *FakeImageEditor) UndoReturns(result1 error) {
+	fake.undoMutex.Lock()
+	defer fake.undoMutex.Unlock()
+	fake.UndoStub = nil
+	fake.undoReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageEditor) UndoReturnsOnCall(i int, result1 error) {
+	fake.undoMutex.Lock()
+	defer fake.undoMutex.Unlock()
+	fake.UndoStub = nil
+	if fake.undoReturnsOnCall == nil {
+		fake.undoReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.undoReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeImageEditor) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.addLayerMutex.RLock()
+	defer fake.addLayerMutex.RUnlock()
+	fake.boxMutex.RLock()
+	defer fake.boxMutex.RUnlock()
+	fake.circleMutex.RLock()
+	defer fake.circleMutex.RUnlock()
 	fake.clearMutex.RLock()
 	defer fake.clearMutex.RUnlock()
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
 	fake.createImageMutex.RLock()
 	defer fake.createImageMutex.RUnlock()
-	fake.prettyMutex.RLock()
-	defer fake.prettyMutex.RUnlock()
+	fake.cropMutex.RLock()
+	defer fake.cropMutex.RUnlock()
+	fake.cropToContentMutex.RLock()
+	defer fake.cropToContentMutex.RUnlock()
+	fake.ellipseMutex.RLock()
+	defer fake.ellipseMutex.RUnlock()
+	fake.fillMutex.RLock()
+	defer fake.fillMutex.RUnlock()
+	fake.filledCircleMutex.RLock()
+	defer fake.filledCircleMutex.RUnlock()
+	fake.filledEllipseMutex.RLock()
+	defer fake.filledEllipseMutex.RUnlock()
+	fake.flipHorizontalMutex.RLock()
+	defer fake.flipHorizontalMutex.RUnlock()
+	fake.flipVerticalMutex.RLock()
+	defer fake.flipVerticalMutex.RUnlock()
+	fake.hideLayerMutex.RLock()
+	defer fake.hideLayerMutex.RUnlock()
+	fake.lineMutex.RLock()
+	defer fake.lineMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.mergeLayerMutex.RLock()
+	defer fake.mergeLayerMutex.RUnlock()
+	fake.moveMutex.RLock()
+	defer fake.moveMutex.RUnlock()
+	fake.moveLayerMutex.RLock()
+	defer fake.moveLayerMutex.RUnlock()
+	fake.pNGMutex.RLock()
+	defer fake.pNGMutex.RUnlock()
+	fake.pasteMutex.RLock()
+	defer fake.pasteMutex.RUnlock()
+	fake.pastePictureMutex.RLock()
+	defer fake.pastePictureMutex.RUnlock()
+	fake.pictureMutex.RLock()
+	defer fake.pictureMutex.RUnlock()
+	fake.rectMutex.RLock()
+	defer fake.rectMutex.RUnlock()
+	fake.redoMutex.RLock()
+	defer fake.redoMutex.RUnlock()
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
+	fake.renderRegionMutex.RLock()
+	defer fake.renderRegionMutex.RUnlock()
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	fake.scaleMutex.RLock()
+	defer fake.scaleMutex.RUnlock()
+	fake.selectLayerMutex.RLock()
+	defer fake.selectLayerMutex.RUnlock()
 	fake.setMutex.RLock()
 	defer fake.setMutex.RUnlock()
 	fake.setMultiXMutex.RLock()
 	defer fake.setMultiXMutex.RUnlock()
 	fake.setMultiYMutex.RLock()
 	defer fake.setMultiYMutex.RUnlock()
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	fake.transposeMutex.RLock()
+	defer fake.transposeMutex.RUnlock()
+	fake.undoMutex.RLock()
+	defer fake.undoMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,273 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxCalls limits how deeply macros may call one another, so that a macro
+// calling itself fails rather than running forever.
+const maxCalls = 100
+
+var (
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	reference  = regexp.MustCompile(`\$[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// keywords are the parts of the language that are not image commands.
+var keywords = []struct {
+	name, usage, help string
+}{
+	{"SET", "SET name value", "Sets a variable, used as $name in later commands."},
+	{"REPEAT", "REPEAT k [var]", "Runs the lines up to END k times, counting from 1 to k in $var if given."},
+	{"DEF", "DEF name [params...]", "Defines a command that runs the lines up to END, with each parameter set as a variable."},
+	{"END", "END", "Ends a REPEAT or DEF block."},
+}
+
+// statement is a line of input kept to be run later, as part of a block.
+type statement struct {
+	line  int
+	words []string
+}
+
+// block is a REPEAT or DEF whose lines are being read, waiting for its END.
+type block struct {
+	header statement
+	body   []statement
+	depth  int
+}
+
+type macro struct {
+	params []string
+	body   []statement
+}
+
+// collect adds a line to the open block, running the block once its END is
+// reached.
+func (r *Runner) collect(text []string) error {
+	switch strings.ToUpper(text[0]) {
+	case "REPEAT", "DEF":
+		r.block.depth++
+	case "END":
+		if r.block.depth == 0 {
+			b := r.block
+			r.block = nil
+			return r.close(b)
+		}
+		r.block.depth--
+	}
+
+	r.block.body = append(r.block.body, statement{line: r.line, words: text})
+
+	return nil
+}
+
+func (r *Runner) close(b *block) error {
+	defer r.at(b.header.line)()
+
+	if strings.ToUpper(b.header.words[0]) == "REPEAT" {
+		return r.repeat(b)
+	}
+
+	return r.define(b)
+}
+
+func (r *Runner) repeat(b *block) error {
+	words, err := r.expand(b.header.words)
+	if err != nil {
+		return err
+	}
+
+	if len(words) < 2 || len(words) > 3 {
+		return &ParseError{fmt.Errorf("REPEAT expects a count and an optional variable, got %d", len(words)-1)}
+	}
+
+	count, err := eval(words[1])
+	if err != nil {
+		return &ParseError{err}
+	}
+	if count < 0 {
+		return &ParseError{errors.New("REPEAT count must not be negative")}
+	}
+
+	var names []string
+	if len(words) == 3 {
+		if !identifier.MatchString(words[2]) {
+			return &ParseError{fmt.Errorf("invalid variable name '%s'", words[2])}
+		}
+		names = append(names, words[2])
+	}
+
+	for i := 1; i <= count; i++ {
+		if err := r.spend(); err != nil {
+			return err
+		}
+
+		restore := r.bind(names, []string{strconv.Itoa(i)})
+		err := r.runBody(b.body)
+		restore()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) define(b *block) error {
+	words := b.header.words
+	if len(words) < 2 {
+		return &ParseError{errors.New("DEF expects a name and its parameters")}
+	}
+
+	name := strings.ToUpper(words[1])
+	if !identifier.MatchString(name) {
+		return &ParseError{fmt.Errorf("invalid command name '%s'", words[1])}
+	}
+	if builtin(name) {
+		return &ParseError{fmt.Errorf("cannot redefine built-in command '%s'", name)}
+	}
+
+	for _, param := range words[2:] {
+		if !identifier.MatchString(param) {
+			return &ParseError{fmt.Errorf("invalid parameter name '%s'", param)}
+		}
+	}
+
+	r.macros[name] = macro{params: words[2:], body: b.body}
+
+	return nil
+}
+
+func (r *Runner) call(name string, m macro, args []string) error {
+	if len(args) != len(m.params) {
+		return &ParseError{fmt.Errorf("%s expects %d arguments, got %d", name, len(m.params), len(args))}
+	}
+
+	if r.calls >= maxCalls {
+		return fmt.Errorf("%s: macro calls nested too deeply", name)
+	}
+	r.calls++
+	defer func() { r.calls-- }()
+
+	defer r.bind(m.params, args)()
+
+	return r.runBody(m.body)
+}
+
+// runBody runs the lines of a block, reporting each failure against the line
+// it came from.
+func (r *Runner) runBody(body []statement) error {
+	for _, s := range body {
+		restore := r.at(s.line)
+		err := r.execute(s.words)
+		if err != nil && !errors.Is(err, ErrBudget) {
+			err = r.report(err)
+		}
+		restore()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) setVariable(args []string) error {
+	if len(args) != 2 {
+		return &ParseError{fmt.Errorf("SET expects a name and a value, got %d", len(args))}
+	}
+
+	name, value := args[0], args[1]
+	if !identifier.MatchString(name) {
+		return &ParseError{fmt.Errorf("invalid variable name '%s'", name)}
+	}
+
+	// Sums are worked out now, so that "SET n $n+1" counts rather than
+	// growing a longer and longer expression.
+	if n, err := eval(value); err == nil {
+		value = strconv.Itoa(n)
+	}
+	r.vars[name] = value
+
+	return nil
+}
+
+// expand replaces each $name in the words with the variable's value.
+func (r *Runner) expand(words []string) ([]string, error) {
+	var err error
+
+	expanded := make([]string, len(words))
+	for i, word := range words {
+		if !strings.Contains(word, "$") {
+			expanded[i] = word
+			continue
+		}
+
+		expanded[i] = reference.ReplaceAllStringFunc(word, func(ref string) string {
+			value, ok := r.vars[ref[1:]]
+			if !ok && err == nil {
+				err = &ParseError{fmt.Errorf("undefined variable '%s'", ref)}
+			}
+			return value
+		})
+	}
+
+	return expanded, err
+}
+
+// bind sets each name to its value, returning a func that puts back whatever
+// the names held before.
+func (r *Runner) bind(names, values []string) func() {
+	type saved struct {
+		value string
+		ok    bool
+	}
+
+	previous := make([]saved, len(names))
+	for i, name := range names {
+		value, ok := r.vars[name]
+		previous[i] = saved{value, ok}
+		r.vars[name] = values[i]
+	}
+
+	return func() {
+		for i := len(names) - 1; i >= 0; i-- {
+			if previous[i].ok {
+				r.vars[names[i]] = previous[i].value
+			} else {
+				delete(r.vars, names[i])
+			}
+		}
+	}
+}
+
+// at points error reports at the given line, until the returned func is
+// called.
+func (r *Runner) at(line int) func() {
+	saved := r.line
+	r.line = line
+
+	return func() {
+		r.line = saved
+	}
+}
+
+func builtin(name string) bool {
+	for _, a := range actions {
+		if a.name == name {
+			return true
+		}
+	}
+	for _, k := range keywords {
+		if k.name == name {
+			return true
+		}
+	}
+
+	return false
+}
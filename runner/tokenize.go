@@ -0,0 +1,18 @@
+package runner
+
+import "strings"
+
+// tokenize splits a line into words separated by any run of spaces or tabs.
+// A word starting with '#' begins a comment, which runs to the end of the
+// line and is dropped.
+func tokenize(line string) []string {
+	words := strings.Fields(line)
+
+	for i, word := range words {
+		if strings.HasPrefix(word, "#") {
+			return words[:i]
+		}
+	}
+
+	return words
+}
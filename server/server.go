@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mo-work/go-technical-test-for-claudia/editor"
+	"github.com/mo-work/go-technical-test-for-claudia/runner"
+)
+
+// Server exposes one image over HTTP. Commands are run by a runner.Runner,
+// so they are parsed exactly as in a script, except that commands touching
+// files are refused. Requests are served one at a time so that concurrent
+// edits cannot interleave, and each may run only so many commands on an image
+// of only so many pixels, printing only so much, so that no request can hold
+// up the rest for long.
+//
+//	POST /image           create the image from {"cols": M, "rows": N}
+//	GET  /image           fetch the image; ?format=json (default), text or png
+//	POST /image/commands  run {"commands": ["L 1 1 A", ...]} in order
+//	POST /image/reset     blank the image, keeping its size
+type Server struct {
+	mu     sync.Mutex
+	runner *runner.Runner
+	out    output
+	mux    *http.ServeMux
+}
+
+// Image is the JSON form of an image: its size and one string per row.
+type Image struct {
+	Cols   int      `json:"cols"`
+	Rows   int      `json:"rows"`
+	Pixels []string `json:"pixels"`
+}
+
+// Result is the outcome of one command in a batch. Output holds anything the
+// command printed, such as the image shown by S.
+type Result struct {
+	Command string `json:"command"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type createRequest struct {
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+}
+
+type commandsRequest struct {
+	Commands []string `json:"commands"`
+}
+
+type commandsResponse struct {
+	Results []Result `json:"results"`
+	Image   Image    `json:"image"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+var (
+	errNoImage = errors.New("no image, create one first")
+	errOutput  = fmt.Errorf("command printed more than %d bytes", maxOutput)
+)
+
+const (
+	// maxSteps is how many commands one request may run, counting each
+	// command inside a REPEAT or macro and each pass of a REPEAT.
+	maxSteps = 10000
+
+	// maxScale bounds the scale of a PNG fetched from the server.
+	maxScale = 16
+
+	// maxOutput bounds what one command may print, which is enough to show
+	// the largest image without colour.
+	maxOutput = 4 << 20
+)
+
+// MaxPixels is the most pixels an image on the server may have, which keeps
+// the work of each command bounded and every image small enough to be sent
+// as JSON.
+const MaxPixels = 1 << 20
+
+// output collects what a command prints, up to maxOutput bytes.
+type output struct {
+	bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	if o.Len()+len(p) > maxOutput {
+		return 0, errOutput
+	}
+
+	return o.Buffer.Write(p)
+}
+
+// New makes a server whose documents are edited by editors from factory.
+func New(factory func() runner.ImageEditor) *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.runner = runner.New(nil, &s.out, factory())
+	s.runner.SetEditorFactory(factory)
+	s.runner.DisableFiles()
+	s.runner.SetStrict(true)
+	s.runner.SetMaxPixels(MaxPixels)
+
+	s.mux.HandleFunc("/image", s.image)
+	s.mux.HandleFunc("/image/commands", s.commands)
+	s.mux.HandleFunc("/image/reset", s.reset)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runner.SetBudget(maxSteps)
+	s.mux.ServeHTTP(w, req)
+}
+
+func (s *Server) image(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		s.fetch(w, req)
+	case http.MethodPost:
+		s.create(w, req)
+	default:
+		allow(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, req *http.Request) {
+	var body createRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+
+	if err := s.runner.Execute(fmt.Sprintf("I %d %d", body.Cols, body.Rows)); err != nil {
+		reply(w, status(err), errorResponse{err.Error()})
+		return
+	}
+
+	reply(w, http.StatusCreated, s.snapshot())
+}
+
+func (s *Server) fetch(w http.ResponseWriter, req *http.Request) {
+	if !s.ready() {
+		reply(w, http.StatusConflict, errorResponse{errNoImage.Error()})
+		return
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		reply(w, http.StatusOK, s.snapshot())
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := s.runner.Editor().Render(w); err != nil {
+			// The response has begun, so all that can be done is to cut it
+			// short.
+			panic(http.ErrAbortHandler)
+		}
+	case "png":
+		scale := 1
+		if text := req.URL.Query().Get("scale"); text != "" {
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				reply(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("could not parse non-integer '%s'", text)})
+				return
+			}
+			scale = n
+		}
+		if scale < 1 || scale > maxScale {
+			reply(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("scale must be between 1 and %d", maxScale)})
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := s.runner.Editor().PNG(&buf, scale); err != nil {
+			reply(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	default:
+		reply(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("unknown format '%s', use json, text or png", format)})
+	}
+}
+
+// commands runs a batch of commands in order, stopping at the first that
+// fails. Those already run are kept.
+func (s *Server) commands(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		allow(w, http.MethodPost)
+		return
+	}
+
+	var body commandsRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if !s.ready() {
+		reply(w, http.StatusConflict, errorResponse{errNoImage.Error()})
+		return
+	}
+
+	code := http.StatusOK
+	response := commandsResponse{Results: []Result{}}
+	for _, command := range body.Commands {
+		s.out.Reset()
+		err := s.runner.Execute(command)
+
+		result := Result{Command: command, Output: s.out.String()}
+		if err != nil {
+			result.Error = err.Error()
+			code = status(err)
+		}
+		response.Results = append(response.Results, result)
+
+		if err != nil {
+			break
+		}
+	}
+
+	// A block left open would swallow the next request's commands.
+	s.out.Reset()
+	if err := s.runner.Flush(); err != nil && code == http.StatusOK {
+		code = status(err)
+		response.Results = append(response.Results, Result{Error: err.Error()})
+	}
+
+	response.Image = s.snapshot()
+	reply(w, code, response)
+}
+
+func (s *Server) reset(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		allow(w, http.MethodPost)
+		return
+	}
+	if !s.ready() {
+		reply(w, http.StatusConflict, errorResponse{errNoImage.Error()})
+		return
+	}
+
+	cols, rows := s.runner.Size()
+	if err := s.runner.Execute(fmt.Sprintf("I %d %d", cols, rows)); err != nil {
+		reply(w, status(err), errorResponse{err.Error()})
+		return
+	}
+
+	reply(w, http.StatusOK, s.snapshot())
+}
+
+func (s *Server) ready() bool {
+	cols, rows := s.runner.Size()
+	return cols > 0 && rows > 0
+}
+
+func (s *Server) snapshot() Image {
+	cols, rows := s.runner.Size()
+	image := Image{Cols: cols, Rows: rows}
+
+	var text strings.Builder
+	if err := s.runner.Editor().Render(&text); err != nil {
+		return image
+	}
+	image.Pixels = strings.Split(strings.TrimSuffix(text.String(), "\n"), "\n")
+
+	return image
+}
+
+// status maps a failed command to the HTTP status that best describes it.
+func status(err error) int {
+	var (
+		parseErr *runner.ParseError
+		fileErr  *runner.FileError
+	)
+
+	switch {
+	case errors.As(err, &parseErr), errors.Is(err, editor.ErrUnknownColour):
+		return http.StatusBadRequest
+	case errors.As(err, &fileErr):
+		return http.StatusForbidden
+	case errors.Is(err, editor.ErrOutOfBounds), errors.Is(err, runner.ErrImageSize), errors.Is(err, runner.ErrArgumentRange),
+		errors.Is(err, runner.ErrImagePixels), errors.Is(err, runner.ErrBudget), errors.Is(err, errOutput):
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusConflict
+}
+
+func allow(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	reply(w, http.StatusMethodNotAllowed, errorResponse{"method not allowed"})
+}
+
+func reply(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,313 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/mo-work/go-technical-test-for-claudia/editor"
+	"github.com/mo-work/go-technical-test-for-claudia/runner"
+	"github.com/mo-work/go-technical-test-for-claudia/server"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var s *server.Server
+
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder, into interface{}) {
+		Expect(json.Unmarshal(rec.Body.Bytes(), into)).To(Succeed())
+	}
+
+	type commandsResponse struct {
+		Results []server.Result
+		Image   server.Image
+	}
+
+	BeforeEach(func() {
+		s = server.New(func() runner.ImageEditor { return &editor.Editor{} })
+	})
+
+	Describe("POST /image", func() {
+		It("creates a blank image", func() {
+			rec := send("POST", "/image", `{"cols": 3, "rows": 2}`)
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+
+			var image server.Image
+			decode(rec, &image)
+			Expect(image).To(Equal(server.Image{Cols: 3, Rows: 2, Pixels: []string{"OOO", "OOO"}}))
+		})
+
+		Context("if the image has too many pixels", func() {
+			It("fails", func() {
+				rec := send("POST", "/image", `{"cols": 2048, "rows": 1024}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(rec.Body.String()).To(ContainSubstring("image has too many pixels"))
+			})
+
+			It("accepts an image of exactly MaxPixels", func() {
+				rec := send("POST", "/image", `{"cols": 1024, "rows": 1024}`)
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+
+				var image server.Image
+				decode(rec, &image)
+				Expect(image.Pixels).To(HaveLen(1024))
+			})
+		})
+
+		Context("if the size is out of range", func() {
+			It("fails", func() {
+				rec := send("POST", "/image", `{"cols": 0, "rows": 2}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(rec.Body.String()).To(ContainSubstring("image axis out of range"))
+			})
+		})
+
+		Context("if the body is not JSON", func() {
+			It("fails", func() {
+				rec := send("POST", "/image", `I 3 2`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("POST /image/commands", func() {
+		BeforeEach(func() {
+			Expect(send("POST", "/image", `{"cols": 3, "rows": 2}`).Code).To(Equal(http.StatusCreated))
+		})
+
+		It("runs each command and returns the image", func() {
+			rec := send("POST", "/image/commands", `{"commands": ["L 1 1 A", "H 1 3 2 B", "S"]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var response commandsResponse
+			decode(rec, &response)
+			Expect(response.Results).To(HaveLen(3))
+			Expect(response.Results[2].Output).To(Equal("AOO\nBBB\n\n"))
+			Expect(response.Image.Pixels).To(Equal([]string{"AOO", "BBB"}))
+		})
+
+		It("runs loops and macros within a batch", func() {
+			rec := send("POST", "/image/commands", `{"commands": ["REPEAT 3 i", "L $i 1 A", "END"]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var response commandsResponse
+			decode(rec, &response)
+			Expect(response.Image.Pixels).To(Equal([]string{"AAA", "OOO"}))
+		})
+
+		Context("if a command fails", func() {
+			It("stops there, keeping the commands already run", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["L 1 1 A", "L 9 9 A", "L 2 1 A"]}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+
+				var response commandsResponse
+				decode(rec, &response)
+				Expect(response.Results).To(HaveLen(2))
+				Expect(response.Results[1].Error).To(Equal("given coordinate is beyond image grid"))
+				Expect(response.Image.Pixels).To(Equal([]string{"AOO", "OOO"}))
+			})
+		})
+
+		Context("if a command cannot be parsed", func() {
+			It("fails as a bad request", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["L 1"]}`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("if a colour is not in the palette", func() {
+			It("fails as a bad request", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["L 1 1 red", "L 2 1 RED!"]}`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+				var response commandsResponse
+				decode(rec, &response)
+				Expect(response.Results[1].Error).To(Equal("unknown colour 'RED!'"))
+				Expect(response.Image.Pixels).To(Equal([]string{"ROO", "OOO"}))
+			})
+		})
+
+		Context("if a block is left open", func() {
+			It("fails, and does not swallow the next batch", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["REPEAT 2", "L 1 1 A"]}`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(ContainSubstring("REPEAT without END"))
+
+				rec = send("POST", "/image/commands", `{"commands": ["L 2 2 B"]}`)
+				var response commandsResponse
+				decode(rec, &response)
+				Expect(response.Image.Pixels).To(Equal([]string{"OOO", "OBO"}))
+			})
+		})
+
+		Context("if the commands run too long", func() {
+			It("stops them, and lets the next batch run", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["REPEAT 1000000000", "REPEAT 1000000000", "L 1 1 A", "END", "END"]}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(rec.Body.String()).To(ContainSubstring("command budget used up"))
+
+				rec = send("POST", "/image/commands", `{"commands": ["L 2 2 B"]}`)
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("if a command would make an image with too many pixels", func() {
+			It("refuses to make it", func() {
+				for _, command := range []string{"Z 1025 1024", "K 2048 1024", "I big 2048 1024"} {
+					rec := send("POST", "/image/commands", fmt.Sprintf(`{"commands": ["%s"]}`, command))
+					Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+					Expect(rec.Body.String()).To(ContainSubstring("image has too many pixels"))
+				}
+
+				rec := send("POST", "/image/commands", `{"commands": ["DOCS"]}`)
+				Expect(rec.Body.String()).To(ContainSubstring(`* main 3x2`))
+				Expect(rec.Body.String()).NotTo(ContainSubstring("big"))
+			})
+		})
+
+		Context("if a command prints too much", func() {
+			It("fails", func() {
+				send("POST", "/image", `{"cols": 1024, "rows": 1024}`)
+
+				rec := send("POST", "/image/commands", `{"commands": ["S", "REPEAT 5", "S", "END"]}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+
+				var response commandsResponse
+				decode(rec, &response)
+				Expect(response.Results).To(HaveLen(4))
+				Expect(response.Results[0].Output).To(HaveLen(1025*1024 + 1))
+				Expect(response.Results[3].Error).To(ContainSubstring("command printed more than"))
+			})
+		})
+
+		Context("if a shape is far larger than any image", func() {
+			It("fails as out of range", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["O 1 1 100000000 A", "D 1 1 100000000 1 A"]}`)
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(rec.Body.String()).To(ContainSubstring("out of range"))
+			})
+		})
+
+		Context("if a command touches files", func() {
+			It("is refused", func() {
+				rec := send("POST", "/image/commands", `{"commands": ["W /tmp/image.bmp"]}`)
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+				Expect(rec.Body.String()).To(ContainSubstring("file access is disabled"))
+			})
+		})
+
+		It("is safe to call concurrently", func() {
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			var wg sync.WaitGroup
+			for i := 1; i <= 3; i++ {
+				for j := 0; j < 10; j++ {
+					wg.Add(1)
+					go func(x int) {
+						defer GinkgoRecover()
+						defer wg.Done()
+
+						body := fmt.Sprintf(`{"commands": ["L %d 1 A", "L %d 2 B"]}`, x, x)
+						resp, err := http.Post(srv.URL+"/image/commands", "application/json", strings.NewReader(body))
+						Expect(err).NotTo(HaveOccurred())
+						resp.Body.Close()
+						Expect(resp.StatusCode).To(Equal(http.StatusOK))
+					}(i)
+				}
+			}
+			wg.Wait()
+
+			rec := send("GET", "/image?format=text", "")
+			Expect(rec.Body.String()).To(Equal("AAA\nBBB\n"))
+		})
+	})
+
+	Describe("GET /image", func() {
+		BeforeEach(func() {
+			send("POST", "/image", `{"cols": 2, "rows": 2}`)
+			send("POST", "/image/commands", `{"commands": ["L 1 1 R"]}`)
+		})
+
+		It("returns JSON by default", func() {
+			rec := send("GET", "/image", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var image server.Image
+			decode(rec, &image)
+			Expect(image.Pixels).To(Equal([]string{"RO", "OO"}))
+		})
+
+		It("returns text", func() {
+			rec := send("GET", "/image?format=text", "")
+			Expect(rec.Body.String()).To(Equal("RO\nOO\n"))
+		})
+
+		It("returns a PNG at the given scale", func() {
+			rec := send("GET", "/image?format=png&scale=3", "")
+			Expect(rec.Header().Get("Content-Type")).To(Equal("image/png"))
+
+			img, err := png.Decode(rec.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(img.Bounds().Dx()).To(Equal(6))
+			Expect(img.Bounds().Dy()).To(Equal(6))
+		})
+
+		Context("if the format is unknown", func() {
+			It("fails", func() {
+				rec := send("GET", "/image?format=gif", "")
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("if the PNG scale is too large", func() {
+			It("fails", func() {
+				rec := send("GET", "/image?format=png&scale=100000000", "")
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(ContainSubstring("scale must be between 1 and 16"))
+			})
+		})
+	})
+
+	Describe("POST /image/reset", func() {
+		It("blanks the image, keeping its size", func() {
+			send("POST", "/image", `{"cols": 2, "rows": 2}`)
+			send("POST", "/image/commands", `{"commands": ["L 1 1 R"]}`)
+
+			rec := send("POST", "/image/reset", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var image server.Image
+			decode(rec, &image)
+			Expect(image).To(Equal(server.Image{Cols: 2, Rows: 2, Pixels: []string{"OO", "OO"}}))
+		})
+	})
+
+	Context("before an image is created", func() {
+		It("refuses to fetch, edit or reset it", func() {
+			Expect(send("GET", "/image", "").Code).To(Equal(http.StatusConflict))
+			Expect(send("POST", "/image/commands", `{"commands": ["C"]}`).Code).To(Equal(http.StatusConflict))
+			Expect(send("POST", "/image/reset", "").Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Context("if the method is not allowed", func() {
+		It("says which are", func() {
+			rec := send("DELETE", "/image", "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("GET, POST"))
+		})
+	})
+})